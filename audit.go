@@ -0,0 +1,256 @@
+package namecheap
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// Audit outcomes, used as AuditRecord.Outcome.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// ErrJournalBroken is returned by VerifyJournal when the hash chain of a
+// journal does not hold, that is when records were edited, removed or
+// reordered.
+var ErrJournalBroken = errors.New("audit journal chain is broken")
+
+// AuditRecord describes a call of a command that changes the account.
+type AuditRecord struct {
+	Seq      uint64    `json:"seq"`
+	Time     time.Time `json:"time"`
+	ApiUser  string    `json:"api_user"`
+	UserName string    `json:"user_name"`
+	ClientIp string    `json:"client_ip"`
+	Command  string    `json:"command"`
+	Domain   string    `json:"domain,omitempty"`
+
+	// Params holds the parameters of the call, with the values of secret
+	// ones, such as the ApiKey, redacted.
+	Params url.Values `json:"params"`
+
+	// ChargedAmount is the amount the account was charged, for billable
+	// commands that succeeded.
+	ChargedAmount float64 `json:"charged_amount,omitempty"`
+
+	Outcome   string `json:"outcome"`
+	Error     string `json:"error,omitempty"`
+	ApiErrors []int  `json:"api_errors,omitempty"`
+
+	// PrevHash is the Hash of the previous record of the journal, or ""
+	// for the first one. Hash is the hex SHA-256 of the JSON encoding of
+	// the record with an empty Hash.
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// AuditSink receives a record of every call of a command that changes the
+// account, successful or not. The Seq, PrevHash and Hash fields are left
+// for the sink to fill in.
+type AuditSink interface {
+	Audit(rec AuditRecord) error
+}
+
+// charger is implemented by the results of billable commands.
+type charger interface {
+	chargedAmount() float64
+}
+
+func (r *domainsCreateResponse) chargedAmount() float64 {
+	if r.DomainCreate == nil {
+		return 0
+	}
+	return r.DomainCreate.ChargedAmount
+}
+
+func (r *domainsRenewResponse) chargedAmount() float64 {
+	if r.DomainRenew == nil {
+		return 0
+	}
+	return r.DomainRenew.ChargedAmount
+}
+
+func (r *sslCreateResponse) chargedAmount() float64 {
+	if r.SslCreate == nil {
+		return 0
+	}
+	return r.SslCreate.ChargedAmount
+}
+
+func (r *whoisguardRenewResponse) chargedAmount() float64 {
+	if r.WhoisguardRenew == nil {
+		return 0
+	}
+	return r.WhoisguardRenew.ChargedAmount
+}
+
+// audit passes the record of a finished call to the client's AuditSink.
+// Since the call itself already happened, a failure to record it is not
+// returned but passed to the client's OnAuditError, or logged if it has
+// none.
+func (client *Client) audit(ctx context.Context, inv *Invocation, resp *ApiResponse, info CallInfo, start time.Time) {
+	rec := AuditRecord{
+		Time:      start.UTC(),
+		ApiUser:   inv.Params.Get("ApiUser"),
+		UserName:  info.UserName,
+		ClientIp:  inv.Params.Get("ClientIp"),
+		Command:   info.Command,
+		Domain:    info.Domain,
+		Params:    sanitizeParams(inv.Params),
+		Outcome:   AuditSuccess,
+		ApiErrors: info.ApiErrors,
+	}
+	if info.Err != nil {
+		rec.Outcome = AuditFailure
+		rec.Error = info.Err.Error()
+	} else if c, ok := resp.Result.(charger); ok {
+		rec.ChargedAmount = c.chargedAmount()
+	}
+
+	err := client.Audit.Audit(rec)
+	if err == nil {
+		return
+	}
+	if client.OnAuditError != nil {
+		client.OnAuditError(ctx, rec, err)
+		return
+	}
+	logger := client.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.ErrorContext(ctx, "namecheap audit failed",
+		"command", info.Command, "error", err.Error())
+}
+
+// Journal is an AuditSink that writes records as JSON lines, each one
+// chained to the previous one by its hash, so that editing, removing or
+// reordering records is detected by VerifyJournal. Removing the last
+// records can only be detected by comparing the last hash with a copy kept
+// elsewhere. A Journal is safe for concurrent use.
+type Journal struct {
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	prev string
+}
+
+// NewJournal returns a Journal that starts a new chain in w.
+func NewJournal(w io.Writer) *Journal {
+	return &Journal{w: w}
+}
+
+// OpenJournal opens the journal file at path, creating it if needed, and
+// continues its chain. The existing records are verified first.
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	last, err := VerifyJournal(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	j := &Journal{w: f}
+	if last != nil {
+		j.seq, j.prev = last.Seq, last.Hash
+	}
+	return j, nil
+}
+
+// Audit implements AuditSink.
+func (j *Journal) Audit(rec AuditRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	rec.Seq = j.seq + 1
+	rec.PrevHash = j.prev
+	hash, err := rec.hash()
+	if err != nil {
+		return err
+	}
+	rec.Hash = hash
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := j.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	j.seq, j.prev = rec.Seq, rec.Hash
+	return nil
+}
+
+// Close closes the underlying writer, if it is an io.Closer.
+func (j *Journal) Close() error {
+	if c, ok := j.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// hash returns the hash of rec, ignoring its Hash field.
+func (rec AuditRecord) hash() (string, error) {
+	rec.Hash = ""
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// VerifyJournal checks the hash chain of the journal read from r and
+// returns its last record, or nil if it is empty. The error wraps
+// ErrJournalBroken if the chain does not hold, or if a record holds fields
+// that are not part of an AuditRecord, and thus not covered by its hash.
+func VerifyJournal(r io.Reader) (*AuditRecord, error) {
+	d := json.NewDecoder(r)
+	var last *AuditRecord
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err == io.EOF {
+			return last, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("audit journal record %d: %w", n, err)
+		}
+		var rec AuditRecord
+		rd := json.NewDecoder(bytes.NewReader(raw))
+		rd.DisallowUnknownFields()
+		if err := rd.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("%w: record %d: %v", ErrJournalBroken, n, err)
+		}
+
+		var wantSeq uint64 = 1
+		var wantPrev string
+		if last != nil {
+			wantSeq, wantPrev = last.Seq+1, last.Hash
+		}
+		hash, err := rec.hash()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case rec.Seq != wantSeq:
+			return nil, fmt.Errorf("%w: record %d has sequence number %d", ErrJournalBroken, n, rec.Seq)
+		case rec.PrevHash != wantPrev:
+			return nil, fmt.Errorf("%w: record %d does not follow the previous one", ErrJournalBroken, n)
+		case rec.Hash != hash:
+			return nil, fmt.Errorf("%w: record %d was modified", ErrJournalBroken, n)
+		}
+		last = &rec
+	}
+}
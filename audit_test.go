@@ -0,0 +1,155 @@
+package namecheap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditJournal(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("Command") {
+		case domainsRenew:
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <CommandResponse Type="namecheap.domains.renew">
+    <DomainRenewResult DomainName="example.com" DomainID="1" Renew="true" ChargedAmount="8.88" OrderID="2" TransactionID="3" />
+  </CommandResponse>
+</ApiResponse>`)
+		case domainsDNSSetCustom:
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2016166">Domain is not associated with your account</Error></Errors>
+</ApiResponse>`)
+		default:
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+		}
+	})
+
+	var buf bytes.Buffer
+	client.Audit = NewJournal(&buf)
+
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if _, err := client.DomainRenew("example.com", 1); err != nil {
+		t.Fatalf("DomainRenew returned error: %v", err)
+	}
+	if _, err := client.DomainDNSSetCustom("other", "com", "ns1.example.net"); !errors.Is(err, ErrDomainNotOwned) {
+		t.Fatalf("DomainDNSSetCustom returned %v, want ErrDomainNotOwned", err)
+	}
+	client.DryRun = new(DryRunLog)
+	if _, err := client.DomainRenew("example.com", 1); !errors.Is(err, ErrDryRun) {
+		t.Fatalf("DomainRenew returned %v, want ErrDryRun", err)
+	}
+
+	journal := buf.String()
+	if strings.Count(journal, "\n") != 2 {
+		t.Fatalf("Journal has %d lines, want 2:\n%s", strings.Count(journal, "\n"), journal)
+	}
+	if strings.Contains(journal, "anToken") {
+		t.Errorf("Journal contains the API key:\n%s", journal)
+	}
+
+	last, err := VerifyJournal(strings.NewReader(journal))
+	if err != nil {
+		t.Fatalf("VerifyJournal returned error: %v", err)
+	}
+	if last.Seq != 2 || last.Command != domainsDNSSetCustom || last.Domain != "other.com" ||
+		last.Outcome != AuditFailure || len(last.ApiErrors) != 1 || last.ApiErrors[0] != 2016166 {
+		t.Errorf("Last record is %+v", last)
+	}
+
+	lines := strings.SplitAfter(journal, "\n")
+	if !strings.Contains(lines[0], `"charged_amount":8.88`) || !strings.Contains(lines[0], `"user_name":"anUser"`) {
+		t.Errorf("First record is %s", lines[0])
+	}
+
+	tampered := strings.Replace(journal, `"charged_amount":8.88`, `"charged_amount":0.88`, 1)
+	if _, err := VerifyJournal(strings.NewReader(tampered)); !errors.Is(err, ErrJournalBroken) {
+		t.Errorf("VerifyJournal of an edited journal returned %v, want ErrJournalBroken", err)
+	}
+	added := strings.Replace(journal, `"outcome":`, `"refunded":true,"outcome":`, 1)
+	if _, err := VerifyJournal(strings.NewReader(added)); !errors.Is(err, ErrJournalBroken) {
+		t.Errorf("VerifyJournal of a journal with an added field returned %v, want ErrJournalBroken", err)
+	}
+	if _, err := VerifyJournal(strings.NewReader(lines[1])); !errors.Is(err, ErrJournalBroken) {
+		t.Errorf("VerifyJournal of a journal missing its first record returned %v, want ErrJournalBroken", err)
+	}
+	if _, err := VerifyJournal(strings.NewReader(lines[1] + lines[0])); !errors.Is(err, ErrJournalBroken) {
+		t.Errorf("VerifyJournal of a reordered journal returned %v, want ErrJournalBroken", err)
+	}
+}
+
+// failingSink is an AuditSink that cannot record anything.
+type failingSink struct{}
+
+func (failingSink) Audit(rec AuditRecord) error {
+	return errors.New("disk full")
+}
+
+func TestAuditError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	var failed []AuditRecord
+	client.Audit = failingSink{}
+	client.OnAuditError = func(ctx context.Context, rec AuditRecord, err error) {
+		failed = append(failed, rec)
+	}
+	if _, err := client.DomainDNSSetCustom("domain", "com", "ns1.example.net"); err != nil {
+		t.Fatalf("DomainDNSSetCustom returned error: %v", err)
+	}
+	if len(failed) != 1 || failed[0].Command != domainsDNSSetCustom {
+		t.Errorf("OnAuditError was called with %+v, want the record of the call", failed)
+	}
+}
+
+func TestOpenJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for i := 0; i < 2; i++ {
+		j, err := OpenJournal(path)
+		if err != nil {
+			t.Fatalf("OpenJournal returned error: %v", err)
+		}
+		if err := j.Audit(AuditRecord{Command: domainsRenew, Outcome: AuditSuccess}); err != nil {
+			t.Fatalf("Audit returned error: %v", err)
+		}
+		if err := j.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	last, err := VerifyJournal(f)
+	if err != nil {
+		t.Fatalf("VerifyJournal returned error: %v", err)
+	}
+	if last.Seq != 2 {
+		t.Errorf("Last record has sequence number %d, want 2", last.Seq)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"seq":1,"hash":"bogus"}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenJournal(path); !errors.Is(err, ErrJournalBroken) {
+		t.Errorf("OpenJournal of a broken journal returned %v, want ErrJournalBroken", err)
+	}
+}
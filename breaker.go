@@ -0,0 +1,149 @@
+package namecheap
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending anything when the
+// CircuitBreaker of a client is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every request with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through, whose outcome
+	// closes the circuit or opens it again.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker stops a client from sending requests to an API that keeps
+// failing. It opens after a number of consecutive failed requests, that is
+// transport errors and 5xx responses, and fails the following ones fast
+// with ErrCircuitOpen. After a cooldown it lets a probe request through:
+// the circuit closes again if the probe succeeds, and stays open for
+// another cooldown otherwise.
+//
+// Requests abandoned by their caller, through the cancellation or deadline
+// of their context, count as neither failures nor successes, and so do
+// requests that finish after the circuit opened or closed since they were
+// let through: only the probe closes an open circuit. A
+// CircuitBreaker is safe for concurrent use and can be shared by several
+// clients that use the same endpoint.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	// generation changes every time the circuit opens or closes, so that
+	// the outcomes of the requests let through before are ignored.
+	generation uint64
+	now        func() time.Time
+}
+
+// circuitTicket is given by allow to a request it lets through, and passed
+// back to record with the outcome of the request.
+type circuitTicket struct {
+	// probe tells whether the request is the probe of a half-open circuit.
+	probe      bool
+	generation uint64
+}
+
+// NewCircuitBreaker returns a CircuitBreaker that opens after threshold
+// consecutive failures, and probes the API again cooldown after opening.
+// A threshold below 1 is treated as 1.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// State returns the current state of the circuit, for example for health
+// checks.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a request may be sent, and returns its ticket.
+// Every request allowed must be followed by a call to record.
+func (b *CircuitBreaker) allow() (circuitTicket, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		b.state = CircuitHalfOpen
+	}
+	ticket := circuitTicket{generation: b.generation}
+	switch b.state {
+	case CircuitOpen:
+		return ticket, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probing {
+			return ticket, ErrCircuitOpen
+		}
+		b.probing = true
+		ticket.probe = true
+	}
+	return ticket, nil
+}
+
+// record records the outcome of a request allowed by allow: the status
+// code of its response, if any, and its error.
+func (b *CircuitBreaker) record(ticket circuitTicket, status int, err error) {
+	failed := status >= 500 || isTransportError(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ticket.probe {
+		b.probing = false
+	}
+	if ticket.generation != b.generation {
+		return
+	}
+	switch {
+	case failed:
+		b.failures++
+		if ticket.probe || b.failures >= b.threshold {
+			b.state, b.openedAt = CircuitOpen, b.now()
+			b.generation++
+		}
+	case err == nil:
+		b.failures = 0
+		if b.state != CircuitClosed {
+			b.state = CircuitClosed
+			b.generation++
+		}
+	}
+}
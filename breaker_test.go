@@ -0,0 +1,132 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests, status := 0, http.StatusServiceUnavailable
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	breaker := NewCircuitBreaker(2, time.Minute)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	client.CircuitBreaker = breaker
+	client.RetryPolicy = testRetryPolicy
+
+	// The retries of the first call trip the breaker before they are used up.
+	if _, err := client.DomainsGetList(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("DomainsGetList returned %v, want ErrCircuitOpen", err)
+	}
+	if requests != 2 {
+		t.Errorf("Sent %d requests, want 2", requests)
+	}
+	if _, err := client.DomainsGetList(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("DomainsGetList of an open circuit returned %v, want ErrCircuitOpen", err)
+	}
+	if requests != 2 || breaker.State() != CircuitOpen {
+		t.Errorf("Sent %d requests with the circuit %v, want 2 with it open", requests, breaker.State())
+	}
+
+	// A failed probe opens the circuit again.
+	now = now.Add(time.Minute)
+	if state := breaker.State(); state != CircuitHalfOpen {
+		t.Errorf("State after the cooldown = %v, want half-open", state)
+	}
+	if _, err := client.DomainsGetList(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("DomainsGetList of a half-open circuit returned %v, want ErrCircuitOpen after the probe", err)
+	}
+	if requests != 3 || breaker.State() != CircuitOpen {
+		t.Errorf("Sent %d requests with the circuit %v, want 3 with it open", requests, breaker.State())
+	}
+
+	// A successful one closes it.
+	now = now.Add(time.Minute)
+	status = http.StatusOK
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Errorf("DomainsGetList returned error: %v", err)
+	}
+	if requests != 4 || breaker.State() != CircuitClosed {
+		t.Errorf("Sent %d requests with the circuit %v, want 4 with it closed", requests, breaker.State())
+	}
+}
+
+func TestCircuitBreakerTransportErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	client.HttpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return nil, errors.New("connection reset by peer")
+	})}
+	client.CircuitBreaker = NewCircuitBreaker(3, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		client.DomainsGetList()
+	}
+	if requests != 3 || client.CircuitBreaker.State() != CircuitOpen {
+		t.Errorf("Sent %d requests with the circuit %v, want 3 with it open", requests, client.CircuitBreaker.State())
+	}
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestCircuitBreakerProbes(t *testing.T) {
+	breaker := NewCircuitBreaker(1, time.Minute)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	// Abandoned requests count for nothing.
+	ticket, _ := breaker.allow()
+	breaker.record(ticket, 0, context.Canceled)
+	if state := breaker.State(); state != CircuitClosed {
+		t.Fatalf("State after a canceled request = %v, want closed", state)
+	}
+
+	slow, _ := breaker.allow()
+	ticket, _ = breaker.allow()
+	breaker.record(ticket, http.StatusBadGateway, nil)
+	if _, err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow of an open circuit returned %v, want ErrCircuitOpen", err)
+	}
+
+	// Requests let through before the circuit opened do not close it.
+	breaker.record(slow, http.StatusOK, nil)
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("State after a late success = %v, want open", state)
+	}
+
+	now = now.Add(time.Minute)
+	ticket, err := breaker.allow()
+	if err != nil || !ticket.probe {
+		t.Fatalf("allow of a half-open circuit returned %+v, %v, want a probe", ticket, err)
+	}
+	if _, err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow during a probe returned %v, want ErrCircuitOpen", err)
+	}
+	breaker.record(ticket, 0, context.DeadlineExceeded)
+	if ticket, err = breaker.allow(); err != nil || !ticket.probe {
+		t.Fatalf("allow after an abandoned probe returned %+v, %v, want another probe", ticket, err)
+	}
+	breaker.record(ticket, http.StatusOK, nil)
+	if state := breaker.State(); state != CircuitClosed {
+		t.Errorf("State after a successful probe = %v, want closed", state)
+	}
+}
@@ -0,0 +1,292 @@
+package namecheap
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTLs are the commands cached by a Cache made by NewCache with
+// no TTLs of its own, and for how long.
+var DefaultCacheTTLs = map[string]time.Duration{
+	domainsGetList:     5 * time.Minute,
+	domainsDNSGetHosts: 5 * time.Minute,
+	domainsTLDList:     24 * time.Hour,
+	usersGetPricing:    time.Hour,
+}
+
+// cacheInvalidations lists, for the commands that change the account, the
+// read-only commands whose responses they make stale. Responses about a
+// domain are only invalidated by changes to the same domain. Commands
+// missing from the list invalidate every response of the account.
+var cacheInvalidations = map[string][]string{
+	domainsDNSSetHosts:  {domainsDNSGetHosts, domainsGetInfo},
+	domainsDNSSetCustom: {domainsDNSGetHosts, domainsGetInfo, domainsGetList},
+	domainsSetContacts:  {domainsGetInfo},
+	domainsCreate:       {domainsGetList, domainsGetInfo, domainsCheck, domainsDNSGetHosts, whoisguardGetList},
+	domainsRenew:        {domainsGetList, domainsGetInfo},
+	sslCreate:           {sslGetList},
+	sslActivate:         {sslGetList},
+	whoisguardEnable:    {whoisguardGetList, domainsGetInfo, domainsGetList},
+	whoisguardDisable:   {whoisguardGetList, domainsGetInfo, domainsGetList},
+	whoisguardRenew:     {whoisguardGetList, domainsGetInfo, domainsGetList},
+}
+
+// Cache is a read-through cache of the responses to read-only commands.
+// Responses are cached per command and parameters, including the API user
+// and user name, so a Cache can be shared by clients of different accounts.
+// They are invalidated when a call that changes the account succeeds, and
+// dropped once expired whenever a response is cached. Concurrent identical
+// calls are sent only once, unless the caller that sent it gives up on it,
+// in which case it is sent again for the others. A Cache is safe for
+// concurrent use.
+type Cache struct {
+	mu   sync.Mutex
+	ttls map[string]time.Duration
+	// entries holds the cached responses, and calls the calls in flight,
+	// by cacheKey.
+	entries map[string]*cacheEntry
+	calls   map[string]*cacheCall
+	now     func() time.Time
+}
+
+// cacheScope identifies what a response is about, for invalidation.
+type cacheScope struct {
+	account string
+	command string
+	domain  string
+}
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	cacheScope
+	expires time.Time
+	resp    ApiResponse
+	result  rawResult
+}
+
+// rawResult captures the CommandResponse element of a response as received,
+// to decode it anew for every call it answers.
+type rawResult struct {
+	Type string `xml:"Type,attr"`
+	Raw  []byte `xml:",innerxml"`
+}
+
+// cacheCall is a call in flight that identical calls wait for.
+type cacheCall struct {
+	cacheScope
+	done  chan struct{}
+	entry *cacheEntry
+	err   error
+}
+
+// NewCache returns a Cache that keeps the responses to the commands of ttls
+// for the given durations, or those of DefaultCacheTTLs if ttls is nil.
+// Commands are named as by the API, such as "namecheap.domains.getList".
+func NewCache(ttls map[string]time.Duration) *Cache {
+	if ttls == nil {
+		ttls = DefaultCacheTTLs
+	}
+	c := &Cache{
+		ttls:    make(map[string]time.Duration, len(ttls)),
+		entries: make(map[string]*cacheEntry),
+		calls:   make(map[string]*cacheCall),
+		now:     time.Now,
+	}
+	for command, ttl := range ttls {
+		c.ttls[command] = ttl
+	}
+	return c
+}
+
+// Purge drops every cached response.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*cacheEntry)
+	c.calls = make(map[string]*cacheCall)
+}
+
+// intercept answers inv from the cache when it can, and otherwise calls
+// next, caching its response or invalidating the responses it makes stale.
+func (c *Cache) intercept(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error) {
+	scope := cacheScope{
+		account: inv.Params.Get("ApiUser") + "/" + inv.Params.Get("UserName"),
+		command: inv.Command,
+		domain:  paramsDomain(inv.Params),
+	}
+	if inv.kind != ReadOnlyCommand {
+		resp, err := next(ctx, inv)
+		if err == nil {
+			c.invalidate(scope)
+		}
+		return resp, err
+	}
+	ttl := c.ttls[inv.Command]
+	if ttl <= 0 || !cacheable(inv.Result) || inv.noCache {
+		return next(ctx, inv)
+	}
+
+	key := cacheKey(inv.Params)
+	for {
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok {
+			if c.now().Before(entry.expires) {
+				c.mu.Unlock()
+				return entry.answer(ctx, inv)
+			}
+			delete(c.entries, key)
+		}
+		if call, ok := c.calls[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			// The call was given up by the caller that sent it. Its error
+			// is not ours, so send the call again.
+			if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+				if ctx.Err() == nil {
+					continue
+				}
+			}
+			if call.err != nil {
+				return nil, call.err
+			}
+			return call.entry.answer(ctx, inv)
+		}
+		call := &cacheCall{cacheScope: scope, done: make(chan struct{})}
+		c.calls[key] = call
+		c.mu.Unlock()
+
+		call.entry, call.err = c.fetch(ctx, inv, next, scope, ttl)
+
+		c.mu.Lock()
+		// A call that is no longer in flight was invalidated while it was, so
+		// its response may already be stale.
+		if c.calls[key] == call {
+			delete(c.calls, key)
+			if call.err == nil {
+				c.evictExpired()
+				c.entries[key] = call.entry
+			}
+		}
+		c.mu.Unlock()
+		close(call.done)
+
+		if call.err != nil {
+			return nil, call.err
+		}
+		return call.entry.answer(ctx, inv)
+	}
+}
+
+// evictExpired drops the expired responses, so that responses that are not
+// asked for again do not stay in memory. c.mu must be held.
+func (c *Cache) evictExpired() {
+	now := c.now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// fetch calls next and captures its response as a cache entry.
+func (c *Cache) fetch(ctx context.Context, inv *Invocation, next Invoker, scope cacheScope, ttl time.Duration) (*cacheEntry, error) {
+	target := inv.Result
+	var raw rawResult
+	inv.Result = &raw
+	resp, err := next(ctx, inv)
+	inv.Result = target
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &cacheEntry{
+		cacheScope: scope,
+		expires:    c.now().Add(ttl),
+		resp:       *resp,
+		result:     raw,
+	}
+	entry.resp.Result = nil
+	return entry, nil
+}
+
+// invalidate drops the responses made stale by the successful call of a
+// command that changed the account.
+func (c *Cache) invalidate(write cacheScope) {
+	reads, known := cacheInvalidations[write.command]
+	stale := func(s cacheScope) bool {
+		if s.account != write.account {
+			return false
+		}
+		if !known {
+			return true
+		}
+		if s.domain != "" && write.domain != "" && !strings.EqualFold(s.domain, write.domain) {
+			return false
+		}
+		for _, command := range reads {
+			if s.command == command {
+				return true
+			}
+		}
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictExpired()
+	for key, entry := range c.entries {
+		if stale(entry.cacheScope) {
+			delete(c.entries, key)
+		}
+	}
+	for key, call := range c.calls {
+		if stale(call.cacheScope) {
+			delete(c.calls, key)
+		}
+	}
+}
+
+// answer decodes the cached response into the result of inv.
+func (e *cacheEntry) answer(ctx context.Context, inv *Invocation) (*ApiResponse, error) {
+	cr := CommandResponse{Type: e.result.Type, Raw: e.result.Raw}
+	if err := cr.Decode(inv.Result); err != nil {
+		return nil, err
+	}
+	resp := e.resp
+	resp.Warnings = append([]ApiError(nil), resp.Warnings...)
+	resp.Result = inv.Result
+	storeMetadata(ctx, resp.ResponseMetadata)
+	return &resp, nil
+}
+
+// cacheable reports whether the responses decoded into result can be
+// cached. Results that decode themselves, such as the streaming one of
+// DomainsGetListFunc, are not, since caching would defeat their purpose.
+func cacheable(result interface{}) bool {
+	if result == nil {
+		return false
+	}
+	_, ok := result.(xml.Unmarshaler)
+	return !ok
+}
+
+// cacheKey identifies a call by its parameters, which include the command
+// and the account, but not the ApiKey, which is redacted, or the ClientIp.
+func cacheKey(params url.Values) string {
+	p := make(url.Values, len(params))
+	for k, v := range params {
+		if k != "ApiKey" && k != "ClientIp" {
+			p[k] = v
+		}
+	}
+	return p.Encode()
+}
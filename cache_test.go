@@ -0,0 +1,264 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cacheServer serves getHosts, getList, setHosts and TLD list responses,
+// counting the requests it receives per command.
+func cacheServer() (counts func(command string) int) {
+	var mu sync.Mutex
+	sent := map[string]int{}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		command := r.FormValue("Command")
+		mu.Lock()
+		sent[command]++
+		mu.Unlock()
+
+		var result string
+		switch command {
+		case domainsDNSGetHosts:
+			result = fmt.Sprintf(`<DomainDNSGetHostsResult Domain="%s.%s" IsUsingOurDNS="true">
+      <host HostId="12" Name="@" Type="A" Address="1.2.3.4" MXPref="10" TTL="1800" />
+    </DomainDNSGetHostsResult>`, r.FormValue("SLD"), r.FormValue("TLD"))
+		case domainsGetList:
+			result = `<DomainGetListResult><Domain ID="1" Name="domain.com" /></DomainGetListResult>`
+		case domainsDNSSetHosts:
+			result = `<DomainDNSSetHostsResult Domain="domain.com" IsSuccess="true" />`
+		case domainsTLDList:
+			time.Sleep(10 * time.Millisecond)
+			result = `<Tlds><Tld Name="com" /></Tlds>`
+		}
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ApiResponse xmlns="http://api.namecheap.com/xml.response" Status="OK">
+  <Errors />
+  <Warnings><Warning Number="1">Cached</Warning></Warnings>
+  <CommandResponse Type="%s">
+    %s
+  </CommandResponse>
+  <ExecutionTime>0.5</ExecutionTime>
+</ApiResponse>`, command, result)
+	})
+	return func(command string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent[command]
+	}
+}
+
+func TestCache(t *testing.T) {
+	setup()
+	defer teardown()
+	counts := cacheServer()
+
+	cache := NewCache(nil)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	client.Cache = cache
+
+	for i := 0; i < 3; i++ {
+		var md ResponseMetadata
+		hosts, err := client.DomainsDNSGetHostsContext(CaptureResponseMetadata(context.Background(), &md), "domain", "com")
+		if err != nil {
+			t.Fatalf("DomainsDNSGetHosts returned error: %v", err)
+		}
+		if hosts.Domain != "domain.com" || len(hosts.Hosts) != 1 || hosts.Hosts[0].Address != "1.2.3.4" {
+			t.Fatalf("DomainsDNSGetHosts returned %+v", hosts)
+		}
+		if md.ExecutionTime != "0.5" || len(md.Warnings) != 1 {
+			t.Errorf("Captured metadata %+v", md)
+		}
+		// Results must not share memory with the cache.
+		hosts.Hosts[0].Address = "5.6.7.8"
+	}
+	if n := counts(domainsDNSGetHosts); n != 1 {
+		t.Errorf("getHosts sent %d times, want 1", n)
+	}
+
+	if _, err := client.DomainsDNSGetHosts("other", "com"); err != nil {
+		t.Fatal(err)
+	}
+	client.UserName = "anotherUser"
+	if _, err := client.DomainsDNSGetHosts("domain", "com"); err != nil {
+		t.Fatal(err)
+	}
+	client.UserName = "anUser"
+	if n := counts(domainsDNSGetHosts); n != 3 {
+		t.Errorf("getHosts sent %d times, want 3 after calls for another domain and user", n)
+	}
+
+	now = now.Add(DefaultCacheTTLs[domainsDNSGetHosts])
+	if _, err := client.DomainsDNSGetHosts("domain", "com"); err != nil {
+		t.Fatal(err)
+	}
+	if n := counts(domainsDNSGetHosts); n != 4 {
+		t.Errorf("getHosts sent %d times, want 4 after the TTL", n)
+	}
+
+	// Commands without a TTL are not cached.
+	for i := 0; i < 2; i++ {
+		if _, err := client.DomainGetInfo("domain.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := counts(domainsGetInfo); n != 2 {
+		t.Errorf("getInfo sent %d times, want 2", n)
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	setup()
+	defer teardown()
+	counts := cacheServer()
+	client.Cache = NewCache(nil)
+
+	load := func() {
+		for _, sld := range []string{"domain", "other"} {
+			if _, err := client.DomainsDNSGetHosts(sld, "com"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := client.DomainsGetList(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	load()
+	if _, err := client.DomainDNSSetHosts("domain", "com", []DomainDNSHost{{Name: "@", Type: "A", Address: "1.2.3.4"}}); err != nil {
+		t.Fatalf("DomainDNSSetHosts returned error: %v", err)
+	}
+	load()
+	if n := counts(domainsDNSGetHosts); n != 3 {
+		t.Errorf("getHosts sent %d times, want 3, once more for the domain whose hosts were set", n)
+	}
+	if n := counts(domainsGetList); n != 1 {
+		t.Errorf("getList sent %d times, want 1", n)
+	}
+
+	// Commands the cache does not know invalidate every response.
+	if _, err := client.Call(context.Background(), "namecheap.domains.setRegistrarLock", nil, nil); err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	load()
+	if n := counts(domainsDNSGetHosts); n != 5 {
+		t.Errorf("getHosts sent %d times, want 5", n)
+	}
+	if n := counts(domainsGetList); n != 2 {
+		t.Errorf("getList sent %d times, want 2", n)
+	}
+
+	client.Cache.Purge()
+	load()
+	if n := counts(domainsGetList); n != 3 {
+		t.Errorf("getList sent %d times after Purge, want 3", n)
+	}
+}
+
+func TestCacheDeduplicatesCalls(t *testing.T) {
+	setup()
+	defer teardown()
+	counts := cacheServer()
+	client.Cache = NewCache(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tlds, err := client.DomainsTLDList()
+			if err != nil {
+				t.Errorf("DomainsTLDList returned error: %v", err)
+			} else if len(tlds) != 1 || tlds[0].Name != "com" {
+				t.Errorf("DomainsTLDList returned %+v", tlds)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := counts(domainsTLDList); n != 1 {
+		t.Errorf("TLD list sent %d times, want 1", n)
+	}
+}
+
+func TestCacheOutlivesCanceledCalls(t *testing.T) {
+	setup()
+	defer teardown()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	var mu sync.Mutex
+	requests := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		first := requests == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-release
+			return
+		}
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ApiResponse xmlns="http://api.namecheap.com/xml.response" Status="OK">
+  <CommandResponse Type="namecheap.domains.getTldList"><Tlds><Tld Name="com" /></Tlds></CommandResponse>
+</ApiResponse>`)
+	})
+	client.Cache = NewCache(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := client.DomainsTLDListContext(ctx)
+		firstErr <- err
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		tlds, err := client.DomainsTLDList()
+		if err == nil && (len(tlds) != 1 || tlds[0].Name != "com") {
+			err = fmt.Errorf("returned %+v", tlds)
+		}
+		waiter <- err
+	}()
+	// Let the second call wait for the first one before canceling it.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Canceled DomainsTLDList returned %v, want context.Canceled", err)
+	}
+	if err := <-waiter; err != nil {
+		t.Errorf("Waiting DomainsTLDList returned error: %v", err)
+	}
+}
+
+func TestCacheEvictsExpiredResponses(t *testing.T) {
+	setup()
+	defer teardown()
+	cacheServer()
+
+	cache := NewCache(nil)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	client.Cache = cache
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.DomainsDNSGetHosts(fmt.Sprintf("domain%d", i), "com"); err != nil {
+			t.Fatalf("DomainsDNSGetHosts returned error: %v", err)
+		}
+		now = now.Add(DefaultCacheTTLs[domainsDNSGetHosts])
+	}
+	cache.mu.Lock()
+	n := len(cache.entries)
+	cache.mu.Unlock()
+	if n != 1 {
+		t.Errorf("Cache holds %d responses, want only the one not expired", n)
+	}
+}
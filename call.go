@@ -0,0 +1,124 @@
+package namecheap
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// CommandResponse is the CommandResponse element of a response to a
+// command sent with Call.
+type CommandResponse struct {
+	// Type is the command the response is for.
+	Type string `xml:"Type,attr"`
+
+	// Raw is the XML inside the element, as received.
+	Raw []byte `xml:",innerxml"`
+
+	// Nodes is the XML inside the element, as a generic tree.
+	Nodes []Node `xml:",any"`
+}
+
+// Decode unmarshals the CommandResponse element into v, as xml.Unmarshal
+// would.
+func (r *CommandResponse) Decode(v interface{}) error {
+	var b strings.Builder
+	b.WriteString(`<CommandResponse Type="`)
+	xml.EscapeText(&b, []byte(r.Type))
+	b.WriteString(`">`)
+	b.Write(r.Raw)
+	b.WriteString(`</CommandResponse>`)
+	return xml.Unmarshal([]byte(b.String()), v)
+}
+
+// Node is an XML element of a generic tree.
+type Node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []Node     `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+// Attr returns the value of the attribute called name, or "" if n has none.
+func (n *Node) Attr(name string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// Find returns the child elements of n with the given local name.
+func (n *Node) Find(name string) []Node {
+	var found []Node
+	for _, child := range n.Nodes {
+		if child.XMLName.Local == name {
+			found = append(found, child)
+		}
+	}
+	return found
+}
+
+// CallOptions are the options of a call made by Call or CallInto.
+type CallOptions struct {
+	// Kind is the kind of the command, which decides whether the call is
+	// retried, cached, sent in dry-run mode and audited. If zero, the
+	// commands this package knows get their own kind, and the others are
+	// treated as BillableCommand, the most conservative choice.
+	Kind CommandKind
+}
+
+// Call sends an arbitrary command, such as one this package does not wrap
+// yet, through the same authentication, transport and interceptors as the
+// other methods. Authentication parameters present in params are replaced.
+// The options can be nil, but should declare the kind of the commands this
+// package does not know, for example:
+//
+//	resp, err := client.Call(ctx, "namecheap.domains.getContacts",
+//		url.Values{"DomainName": {"example.com"}},
+//		&namecheap.CallOptions{Kind: namecheap.ReadOnlyCommand})
+//
+// The result is returned both as raw XML and as a generic tree.
+func (client *Client) Call(ctx context.Context, command string, params url.Values, opts *CallOptions) (*CommandResponse, error) {
+	if command == "" {
+		return nil, errors.New("command cannot be blank")
+	}
+	var result CommandResponse
+	requestInfo := &ApiRequest{
+		command: command,
+		method:  "POST",
+		params:  url.Values{},
+		result:  &result,
+	}
+	for k, v := range params {
+		requestInfo.params[k] = append([]string(nil), v...)
+	}
+	if opts != nil {
+		requestInfo.kind = opts.Kind
+	}
+
+	if _, err := client.do(ctx, requestInfo); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CallInto is like Call but unmarshals the CommandResponse element into v,
+// as xml.Unmarshal would, for example:
+//
+//	var balances struct {
+//		Result struct {
+//			AvailableBalance float64 `xml:"AvailableBalance,attr"`
+//		} `xml:"UserGetBalancesResult"`
+//	}
+//	err := client.CallInto(ctx, "namecheap.users.getBalances", nil, &balances, nil)
+func (client *Client) CallInto(ctx context.Context, command string, params url.Values, v interface{}, opts *CallOptions) error {
+	resp, err := client.Call(ctx, command, params, opts)
+	if err != nil {
+		return err
+	}
+	return resp.Decode(v)
+}
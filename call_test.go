@@ -0,0 +1,155 @@
+package namecheap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const getBalancesResponse = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <RequestedCommand>namecheap.users.getBalances</RequestedCommand>
+  <CommandResponse Type="namecheap.users.getBalances">
+    <UserGetBalancesResult Currency="USD" AvailableBalance="4932.96" AccountBalance="4932.96" />
+  </CommandResponse>
+  <Server>SERVER-NAME</Server>
+  <GMTTimeDifference>+5</GMTTimeDifference>
+  <ExecutionTime>0.024</ExecutionTime>
+</ApiResponse>`
+
+func TestCall(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		correctParams := fillDefaultParams(url.Values{})
+		correctParams.Set("Command", "namecheap.users.getBalances")
+		correctParams.Set("Extra", "value")
+		testBody(t, r, correctParams)
+		fmt.Fprint(w, getBalancesResponse)
+	})
+
+	params := url.Values{"Extra": {"value"}}
+	resp, err := client.Call(context.Background(), "namecheap.users.getBalances", params, nil)
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if len(params) != 1 {
+		t.Errorf("Call modified its params: %v", params)
+	}
+	if resp.Type != "namecheap.users.getBalances" || !strings.Contains(string(resp.Raw), `AvailableBalance="4932.96"`) {
+		t.Errorf("Call returned %+v", resp)
+	}
+	if len(resp.Nodes) != 1 {
+		t.Fatalf("Call returned %d nodes, want 1", len(resp.Nodes))
+	}
+	if n := resp.Nodes[0]; n.XMLName.Local != "UserGetBalancesResult" || n.Attr("Currency") != "USD" {
+		t.Errorf("Call returned node %+v", n)
+	}
+
+	var balances struct {
+		Result struct {
+			AvailableBalance float64 `xml:"AvailableBalance,attr"`
+		} `xml:"UserGetBalancesResult"`
+	}
+	if err := client.CallInto(context.Background(), "namecheap.users.getBalances", params, &balances, nil); err != nil {
+		t.Fatalf("CallInto returned error: %v", err)
+	}
+	if balances.Result.AvailableBalance != 4932.96 {
+		t.Errorf("CallInto decoded AvailableBalance %v, want 4932.96", balances.Result.AvailableBalance)
+	}
+}
+
+func TestCallKind(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var commands []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		commands = append(commands, r.FormValue("Command"))
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <CommandResponse Type="%s" />
+</ApiResponse>`, r.FormValue("Command"))
+	})
+
+	var audit bytes.Buffer
+	client.Audit = NewJournal(&audit)
+	client.Cache = NewCache(map[string]time.Duration{domainsGetList: time.Minute})
+	client.DryRun = new(DryRunLog)
+	ctx := context.Background()
+
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	// Declared reads are sent in dry-run mode, leave the cache alone and
+	// are not audited.
+	if _, err := client.Call(ctx, "namecheap.domains.getContacts", nil, &CallOptions{Kind: ReadOnlyCommand}); err != nil {
+		t.Errorf("Call of a read-only command in dry-run mode returned error: %v", err)
+	}
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if want := []string{domainsGetList, "namecheap.domains.getContacts"}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("Sent %v, want %v", commands, want)
+	}
+	if audit.Len() != 0 {
+		t.Errorf("Audited a read-only command: %s", audit.String())
+	}
+
+	// Undeclared commands are treated as billable.
+	for _, opts := range []*CallOptions{nil, {}} {
+		if _, err := client.Call(ctx, "namecheap.domains.getContacts", nil, opts); !errors.Is(err, ErrDryRun) {
+			t.Errorf("Call of an undeclared command in dry-run mode with options %+v returned %v, want ErrDryRun", opts, err)
+		}
+	}
+	// Known commands keep their kind unless told otherwise.
+	if _, err := client.Call(ctx, domainsGetList, nil, &CallOptions{}); err != nil {
+		t.Errorf("Call of a known read-only command in dry-run mode returned error: %v", err)
+	}
+	if _, err := client.Call(ctx, domainsGetList, nil, &CallOptions{Kind: WriteCommand}); !errors.Is(err, ErrDryRun) {
+		t.Errorf("Call of a command declared as a write in dry-run mode returned %v, want ErrDryRun", err)
+	}
+	if len(commands) != 2 {
+		t.Errorf("Sent %d requests, want 2", len(commands))
+	}
+}
+
+func TestCallErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2019166">Domain not found</Error></Errors>
+</ApiResponse>`)
+	})
+
+	if _, err := client.Call(context.Background(), "namecheap.domains.getRegistrarLock", nil, nil); !errors.Is(err, ErrDomainNotFound) {
+		t.Errorf("Call returned %v, want ErrDomainNotFound", err)
+	}
+	if _, err := client.Call(context.Background(), "", nil, nil); err == nil {
+		t.Error("Call with a blank command returned no error")
+	}
+}
+
+func TestNodeFind(t *testing.T) {
+	n := Node{Nodes: []Node{
+		{XMLName: xml.Name{Local: "Nameserver"}, Text: "a"},
+		{XMLName: xml.Name{Local: "Other"}, Text: "b"},
+	}}
+	if found := n.Find("Nameserver"); len(found) != 1 || found[0].Text != "a" {
+		t.Errorf("Find returned %+v", found)
+	}
+	if v := n.Attr("Missing"); v != "" {
+		t.Errorf("Attr returned %q for a missing attribute", v)
+	}
+}
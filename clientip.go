@@ -0,0 +1,257 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultIPEchoURL is an HTTP endpoint answering with the IP address the
+// request came from, as plain text.
+const DefaultIPEchoURL = "https://api.ipify.org"
+
+// DefaultIPDetectionTTL is how long NewClientFromEnv keeps a detected
+// client IP before detecting it again.
+const DefaultIPDetectionTTL = 5 * time.Minute
+
+// IPDetector finds out the IP address a Client sends its requests from,
+// which is the one the API checks against the whitelist and expects as the
+// ClientIp parameter.
+type IPDetector interface {
+	DetectIP(ctx context.Context) (string, error)
+}
+
+// HTTPEchoDetector is an IPDetector asking an HTTP endpoint, such as
+// DefaultIPEchoURL, which address the request came from. The endpoint must
+// answer with the address as plain text. Its requests should take the same
+// route to the internet as those of the client, so HttpClient is typically
+// the client's own.
+type HTTPEchoDetector struct {
+	URL string
+
+	// HttpClient sends the requests. It defaults to http.DefaultClient.
+	HttpClient *http.Client
+}
+
+// NewHTTPEchoDetector returns an HTTPEchoDetector asking url.
+func NewHTTPEchoDetector(url string) *HTTPEchoDetector {
+	return &HTTPEchoDetector{URL: url}
+}
+
+// DetectIP implements IPDetector.
+func (d *HTTPEchoDetector) DetectIP(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", d.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	httpClient := d.HttpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code from %s: %d", d.URL, resp.StatusCode)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(b)))
+	if ip == nil {
+		return "", fmt.Errorf("%s answered %q, which is not an IP address", d.URL, strings.TrimSpace(string(b)))
+	}
+	return ip.String(), nil
+}
+
+// InterfaceDetector is an IPDetector using the address of a local network
+// interface, for hosts whose interface holds their public address. Private,
+// loopback, link-local and multicast addresses are never used, since the
+// API never sees requests coming from them.
+type InterfaceDetector struct {
+	// Name is the name of the interface, such as "eth0". If empty, the
+	// first interface that is up and has a suitable address is used.
+	Name string
+
+	// IPv6 selects an IPv6 address instead of an IPv4 one.
+	IPv6 bool
+
+	interfaces func() ([]net.Interface, error)
+	addrs      func(net.Interface) ([]net.Addr, error)
+}
+
+// DetectIP implements IPDetector.
+func (d *InterfaceDetector) DetectIP(ctx context.Context) (string, error) {
+	interfaces, addrs := d.interfaces, d.addrs
+	if interfaces == nil {
+		interfaces = net.Interfaces
+	}
+	if addrs == nil {
+		addrs = func(iface net.Interface) ([]net.Addr, error) { return iface.Addrs() }
+	}
+
+	ifaces, err := interfaces()
+	if err != nil {
+		return "", err
+	}
+	for _, iface := range ifaces {
+		if d.Name != "" && iface.Name != d.Name {
+			continue
+		}
+		if d.Name == "" && (iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0) {
+			continue
+		}
+		ifaceAddrs, err := addrs(iface)
+		if err != nil {
+			return "", fmt.Errorf("listing the addresses of %s: %w", iface.Name, err)
+		}
+		for _, addr := range ifaceAddrs {
+			var ip net.IP
+			switch addr := addr.(type) {
+			case *net.IPNet:
+				ip = addr.IP
+			case *net.IPAddr:
+				ip = addr.IP
+			}
+			if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() || (ip.To4() == nil) != d.IPv6 {
+				continue
+			}
+			return ip.String(), nil
+		}
+	}
+	if d.Name != "" {
+		return "", fmt.Errorf("no suitable address on interface %s", d.Name)
+	}
+	return "", fmt.Errorf("no interface with a suitable address")
+}
+
+// CachedIPDetector is an IPDetector keeping the address found by another
+// one for a while before detecting it again, so that the client IP follows
+// changes of the egress address without a detection for every call.
+// Concurrent calls share a single detection, each of them giving up on it
+// when its own context is done. If a detection fails, the last address
+// found keeps being used and detection is tried again by the next call. It
+// is safe for concurrent use.
+type CachedIPDetector struct {
+	detector IPDetector
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	ip      string
+	expires time.Time
+	flight  *ipDetection
+	now     func() time.Time
+}
+
+// ipDetection is a detection in flight that concurrent calls wait for.
+type ipDetection struct {
+	done chan struct{}
+	ip   string
+	err  error
+}
+
+// NewCachedIPDetector returns a CachedIPDetector keeping the addresses
+// found by detector for ttl.
+func NewCachedIPDetector(detector IPDetector, ttl time.Duration) *CachedIPDetector {
+	return &CachedIPDetector{
+		detector: detector,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// DetectIP implements IPDetector.
+func (d *CachedIPDetector) DetectIP(ctx context.Context) (string, error) {
+	for {
+		d.mu.Lock()
+		if d.ip != "" && d.now().Before(d.expires) {
+			ip := d.ip
+			d.mu.Unlock()
+			return ip, nil
+		}
+		d.mu.Unlock()
+
+		ip, retry, err := d.wait(ctx)
+		if retry {
+			continue
+		}
+		if err != nil {
+			d.mu.Lock()
+			last := d.ip
+			d.mu.Unlock()
+			if last != "" {
+				return last, nil
+			}
+		}
+		return ip, err
+	}
+}
+
+// Refresh detects the address again, for example after the API rejected
+// the one in use.
+func (d *CachedIPDetector) Refresh(ctx context.Context) (string, error) {
+	for {
+		ip, retry, err := d.wait(ctx)
+		if !retry {
+			return ip, err
+		}
+	}
+}
+
+// wait joins the detection in flight, or starts one, and waits for it to
+// end or for ctx to be done. It reports whether the detection was given up
+// by the call that started it, in which case another one should be made.
+func (d *CachedIPDetector) wait(ctx context.Context) (ip string, retry bool, err error) {
+	d.mu.Lock()
+	flight := d.flight
+	started := flight == nil
+	if started {
+		flight = &ipDetection{done: make(chan struct{})}
+		d.flight = flight
+	}
+	d.mu.Unlock()
+
+	if started {
+		flight.ip, flight.err = d.detector.DetectIP(ctx)
+		d.mu.Lock()
+		if flight.err == nil {
+			d.ip, d.expires = flight.ip, d.now().Add(d.ttl)
+		}
+		d.flight = nil
+		d.mu.Unlock()
+		close(flight.done)
+	}
+
+	select {
+	case <-flight.done:
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	}
+	if !started && ctx.Err() == nil &&
+		(errors.Is(flight.err, context.Canceled) || errors.Is(flight.err, context.DeadlineExceeded)) {
+		return "", true, nil
+	}
+	return flight.ip, false, flight.err
+}
+
+// clientIP returns the ClientIp of a call, detected by the client's
+// IPDetector if it has one.
+func (client *Client) clientIP(ctx context.Context) (string, error) {
+	if client.IPDetector == nil {
+		return client.ClientIp, nil
+	}
+	ip, err := client.IPDetector.DetectIP(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect client IP: %w", err)
+	}
+	return ip, nil
+}
@@ -0,0 +1,242 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type ipDetectorFunc func(ctx context.Context) (string, error)
+
+func (f ipDetectorFunc) DetectIP(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+func TestClientIPDetection(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var clientIPs []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		clientIPs = append(clientIPs, r.FormValue("ClientIp"))
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	ip := "198.51.100.9"
+	client.IPDetector = ipDetectorFunc(func(ctx context.Context) (string, error) {
+		return ip, nil
+	})
+	client.DomainsGetList()
+	ip = "198.51.100.10"
+	client.DomainsGetList()
+	if len(clientIPs) != 2 || clientIPs[0] != "198.51.100.9" || clientIPs[1] != "198.51.100.10" {
+		t.Errorf("Sent ClientIp %v, want the detected ones", clientIPs)
+	}
+
+	errNoIP := errors.New("no IP")
+	client.IPDetector = ipDetectorFunc(func(ctx context.Context) (string, error) {
+		return "", errNoIP
+	})
+	if _, err := client.DomainsGetList(); !errors.Is(err, errNoIP) {
+		t.Errorf("DomainsGetList returned %v, want the error of the detector", err)
+	}
+	if len(clientIPs) != 2 {
+		t.Errorf("Sent %d requests, want none without a client IP", len(clientIPs)-2)
+	}
+}
+
+func TestHTTPEchoDetector(t *testing.T) {
+	var status int
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+	detector := NewHTTPEchoDetector(srv.URL)
+
+	tests := []struct {
+		status int
+		body   string
+		want   string
+	}{
+		{http.StatusOK, " 203.0.113.5\n", "203.0.113.5"},
+		{http.StatusOK, "2001:db8::1", "2001:db8::1"},
+		{http.StatusOK, "<html>Hello</html>", ""},
+		{http.StatusServiceUnavailable, "203.0.113.5", ""},
+	}
+	for _, tt := range tests {
+		status, body = tt.status, tt.body
+		ip, err := detector.DetectIP(context.Background())
+		if ip != tt.want || (err == nil) != (tt.want != "") {
+			t.Errorf("DetectIP of %d %q returned %q, %v, want %q", tt.status, tt.body, ip, err, tt.want)
+		}
+	}
+}
+
+func TestInterfaceDetector(t *testing.T) {
+	ifaces := []net.Interface{
+		{Name: "lo", Flags: net.FlagUp | net.FlagLoopback},
+		{Name: "eth0"},
+		{Name: "eth1", Flags: net.FlagUp},
+	}
+	addrs := map[string][]net.Addr{
+		"lo":   {&net.IPNet{IP: net.ParseIP("127.0.0.1")}},
+		"eth0": {&net.IPNet{IP: net.ParseIP("203.0.113.1")}},
+		"eth1": {
+			&net.IPNet{IP: net.ParseIP("10.0.0.5")},
+			&net.IPNet{IP: net.ParseIP("fd00::5")},
+			&net.IPNet{IP: net.ParseIP("fe80::1")},
+			&net.IPNet{IP: net.ParseIP("2001:db8::2")},
+			&net.IPNet{IP: net.ParseIP("203.0.113.2")},
+		},
+	}
+
+	tests := []struct {
+		detector InterfaceDetector
+		want     string
+	}{
+		{InterfaceDetector{}, "203.0.113.2"},
+		{InterfaceDetector{IPv6: true}, "2001:db8::2"},
+		{InterfaceDetector{Name: "eth0"}, "203.0.113.1"},
+		{InterfaceDetector{Name: "eth0", IPv6: true}, ""},
+		{InterfaceDetector{Name: "lo"}, ""},
+		{InterfaceDetector{Name: "wlan0"}, ""},
+	}
+	for _, tt := range tests {
+		d := tt.detector
+		d.interfaces = func() ([]net.Interface, error) { return ifaces, nil }
+		d.addrs = func(iface net.Interface) ([]net.Addr, error) { return addrs[iface.Name], nil }
+		ip, err := d.DetectIP(context.Background())
+		if ip != tt.want || (err == nil) != (tt.want != "") {
+			t.Errorf("DetectIP of %+v returned %q, %v, want %q", tt.detector, ip, err, tt.want)
+		}
+	}
+}
+
+func TestCachedIPDetector(t *testing.T) {
+	detections := 0
+	ip, errDetect := "203.0.113.1", error(nil)
+	detector := NewCachedIPDetector(ipDetectorFunc(func(ctx context.Context) (string, error) {
+		detections++
+		return ip, errDetect
+	}), time.Minute)
+	now := time.Now()
+	detector.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if got, err := detector.DetectIP(ctx); got != "203.0.113.1" || err != nil {
+			t.Errorf("DetectIP returned %q, %v, want 203.0.113.1", got, err)
+		}
+	}
+	if detections != 1 {
+		t.Errorf("Detected %d times, want 1", detections)
+	}
+
+	// The address changes.
+	ip = "203.0.113.2"
+	now = now.Add(time.Minute)
+	if got, err := detector.DetectIP(ctx); got != "203.0.113.2" || err != nil {
+		t.Errorf("DetectIP after the TTL returned %q, %v, want 203.0.113.2", got, err)
+	}
+
+	// Failed detections fall back on the last address, except for Refresh.
+	errDetect = errors.New("unreachable")
+	now = now.Add(time.Minute)
+	if got, err := detector.DetectIP(ctx); got != "203.0.113.2" || err != nil {
+		t.Errorf("DetectIP of a failing detector returned %q, %v, want the last address", got, err)
+	}
+	if _, err := detector.Refresh(ctx); !errors.Is(err, errDetect) {
+		t.Errorf("Refresh of a failing detector returned %v, want its error", err)
+	}
+	if detections != 4 {
+		t.Errorf("Detected %d times, want 4", detections)
+	}
+
+	empty := NewCachedIPDetector(ipDetectorFunc(func(ctx context.Context) (string, error) {
+		return "", errDetect
+	}), time.Minute)
+	if _, err := empty.DetectIP(ctx); !errors.Is(err, errDetect) {
+		t.Errorf("DetectIP of a failing detector returned %v, want its error", err)
+	}
+}
+
+func TestCachedIPDetectorConcurrency(t *testing.T) {
+	started := make(chan struct{})
+	var mu sync.Mutex
+	detections := 0
+	detector := NewCachedIPDetector(ipDetectorFunc(func(ctx context.Context) (string, error) {
+		mu.Lock()
+		detections++
+		first := detections == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "203.0.113.1", nil
+	}), time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := detector.DetectIP(ctx)
+		firstErr <- err
+	}()
+	<-started
+
+	// Waiting calls give up with their own context.
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	if _, err := detector.DetectIP(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DetectIP with an expired context returned %v, want context.DeadlineExceeded", err)
+	}
+
+	// And detect again when the call that started the detection gives up.
+	waiter := make(chan string, 1)
+	go func() {
+		ip, err := detector.DetectIP(context.Background())
+		if err != nil {
+			ip = err.Error()
+		}
+		waiter <- ip
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Canceled DetectIP returned %v, want context.Canceled", err)
+	}
+	if ip := <-waiter; ip != "203.0.113.1" {
+		t.Errorf("Waiting DetectIP returned %q, want 203.0.113.1", ip)
+	}
+	if detections != 2 {
+		t.Errorf("Detected %d times, want 2", detections)
+	}
+}
+
+func TestNewClientFromEnvAutoClientIP(t *testing.T) {
+	t.Setenv(EnvApiUser, "anApiUser")
+	t.Setenv(EnvApiKey, "aKey")
+	t.Setenv(EnvClientIp, "auto")
+
+	c, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv returned error: %v", err)
+	}
+	cached, ok := c.IPDetector.(*CachedIPDetector)
+	if !ok {
+		t.Fatalf("IPDetector = %#v, want a CachedIPDetector", c.IPDetector)
+	}
+	if echo, ok := cached.detector.(*HTTPEchoDetector); !ok || echo.URL != DefaultIPEchoURL || cached.ttl != DefaultIPDetectionTTL {
+		t.Errorf("IPDetector = %+v, want one asking %s", cached, DefaultIPEchoURL)
+	}
+}
@@ -0,0 +1,56 @@
+package namecheap
+
+// CommandKind classifies API commands by their side effects on the account.
+// It decides whether calls are retried, cached, sent in dry-run mode and
+// audited. Callers of Call declare it for the commands this package does
+// not know. The zero CommandKind is no kind, and leaves the kind of the
+// command to the package.
+type CommandKind int
+
+const (
+	// ReadOnlyCommand has no side effects.
+	ReadOnlyCommand CommandKind = iota + 1
+	// WriteCommand changes the account but is safe to repeat.
+	WriteCommand
+	// BillableCommand charges the account and must never be repeated blindly.
+	BillableCommand
+)
+
+var commandKinds = map[string]CommandKind{
+	domainsGetList:      ReadOnlyCommand,
+	domainsGetInfo:      ReadOnlyCommand,
+	domainsCheck:        ReadOnlyCommand,
+	domainsTLDList:      ReadOnlyCommand,
+	domainsDNSGetHosts:  ReadOnlyCommand,
+	nsGetInfo:           ReadOnlyCommand,
+	sslGetList:          ReadOnlyCommand,
+	usersGetPricing:     ReadOnlyCommand,
+	usersGetBalances:    ReadOnlyCommand,
+	whoisguardGetList:   ReadOnlyCommand,
+	domainsDNSSetHosts:  WriteCommand,
+	domainsDNSSetCustom: WriteCommand,
+	domainsSetContacts:  WriteCommand,
+	sslActivate:         WriteCommand,
+	whoisguardEnable:    WriteCommand,
+	whoisguardDisable:   WriteCommand,
+	domainsCreate:       BillableCommand,
+	domainsRenew:        BillableCommand,
+	sslCreate:           BillableCommand,
+	whoisguardRenew:     BillableCommand,
+}
+
+// kindOf returns the kind of command. Commands this package does not know
+// about are treated as billable, which is the most conservative choice.
+func kindOf(command string) CommandKind {
+	if kind, ok := commandKinds[command]; ok {
+		return kind
+	}
+	return BillableCommand
+}
+
+// KnownCommand reports whether command is one of the API commands this
+// package wraps, for example to keep the label values of metrics bounded.
+func KnownCommand(command string) bool {
+	_, ok := commandKinds[command]
+	return ok
+}
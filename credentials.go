@@ -0,0 +1,157 @@
+package namecheap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider supplies the API key of a Client. It is consulted
+// for every request, retries included, so the key can be rotated while the
+// client is in use.
+type CredentialsProvider interface {
+	ApiKey(ctx context.Context) (string, error)
+}
+
+// StaticCredentials is a CredentialsProvider holding the API key in memory.
+// The key can be replaced with Rotate. It is safe for concurrent use.
+type StaticCredentials struct {
+	mu  sync.RWMutex
+	key string
+}
+
+// NewStaticCredentials returns StaticCredentials holding apiKey.
+func NewStaticCredentials(apiKey string) *StaticCredentials {
+	return &StaticCredentials{key: apiKey}
+}
+
+// ApiKey implements CredentialsProvider.
+func (c *StaticCredentials) ApiKey(ctx context.Context) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.key, nil
+}
+
+// Rotate replaces the API key used by the following requests.
+func (c *StaticCredentials) Rotate(apiKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key = apiKey
+}
+
+// FileCredentials is a CredentialsProvider reading the API key from a file,
+// such as a mounted secret, with surrounding whitespace trimmed. The file is
+// read again whenever it changes, so the key is rotated by replacing the
+// file. It is safe for concurrent use.
+type FileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	key     string
+}
+
+// NewFileCredentials returns FileCredentials reading the file at path.
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{path: path}
+}
+
+// ApiKey implements CredentialsProvider.
+func (c *FileCredentials) ApiKey(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fi, err := os.Stat(c.path)
+	if err != nil {
+		return "", err
+	}
+	if c.key != "" && fi.ModTime().Equal(c.modTime) && fi.Size() == c.size {
+		return c.key, nil
+	}
+	b, err := os.ReadFile(c.path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s is empty", c.path)
+	}
+	c.key, c.modTime, c.size = key, fi.ModTime(), fi.Size()
+	return key, nil
+}
+
+// EnvCredentials is a CredentialsProvider reading the API key from the
+// environment variable it names, or NAMECHEAP_API_KEY if it is empty. The
+// variable is read for every request.
+type EnvCredentials string
+
+// ApiKey implements CredentialsProvider.
+func (c EnvCredentials) ApiKey(ctx context.Context) (string, error) {
+	name := string(c)
+	if name == "" {
+		name = EnvApiKey
+	}
+	key := os.Getenv(name)
+	if key == "" {
+		return "", fmt.Errorf("%s is not set", name)
+	}
+	return key, nil
+}
+
+// ExecCredentials is a CredentialsProvider running a command, such as the
+// client of a secrets manager, and using its output, with surrounding
+// whitespace trimmed, as the API key. The key is kept for a while before
+// the command is run again. It is safe for concurrent use.
+type ExecCredentials struct {
+	ttl  time.Duration
+	name string
+	args []string
+
+	mu      sync.Mutex
+	key     string
+	expires time.Time
+	now     func() time.Time
+}
+
+// NewExecCredentials returns ExecCredentials running the program name with
+// args, and keeping the key it outputs for ttl. A ttl of zero or less runs
+// the program for every request.
+func NewExecCredentials(ttl time.Duration, name string, args ...string) *ExecCredentials {
+	return &ExecCredentials{
+		ttl:  ttl,
+		name: name,
+		args: append([]string(nil), args...),
+		now:  time.Now,
+	}
+}
+
+// ApiKey implements CredentialsProvider.
+func (c *ExecCredentials) ApiKey(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.key != "" && c.now().Before(c.expires) {
+		return c.key, nil
+	}
+	out, err := exec.CommandContext(ctx, c.name, c.args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("running %s: %w: %s", c.name, err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("running %s: %w", c.name, err)
+	}
+	key := strings.TrimSpace(string(out))
+	if key == "" {
+		return "", fmt.Errorf("running %s: no API key in its output", c.name)
+	}
+	c.key, c.expires = key, c.now().Add(c.ttl)
+	return key, nil
+}
@@ -0,0 +1,164 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCredentialsRotation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var keys []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.FormValue("ApiKey"))
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	creds := NewStaticCredentials("firstKey")
+	client.Credentials = creds
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	creds.Rotate("secondKey")
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "firstKey" || keys[1] != "secondKey" {
+		t.Errorf("Keys sent = %v, want [firstKey secondKey]", keys)
+	}
+
+	errNoKey := errors.New("no key")
+	client.Credentials = credentialsFunc(func(ctx context.Context) (string, error) {
+		return "", errNoKey
+	})
+	if _, err := client.DomainsGetList(); !errors.Is(err, errNoKey) {
+		t.Errorf("DomainsGetList returned %v, want the error of the provider", err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("Request sent without an API key")
+	}
+}
+
+type credentialsFunc func(ctx context.Context) (string, error)
+
+func (f credentialsFunc) ApiKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_key")
+	creds := NewFileCredentials(path)
+	ctx := context.Background()
+
+	if _, err := creds.ApiKey(ctx); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ApiKey of a missing file returned %v, want os.ErrNotExist", err)
+	}
+
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("firstKey\n")
+	if key, err := creds.ApiKey(ctx); err != nil || key != "firstKey" {
+		t.Errorf("ApiKey returned %q, %v, want firstKey", key, err)
+	}
+	write("theSecondKey\n")
+	if key, err := creds.ApiKey(ctx); err != nil || key != "theSecondKey" {
+		t.Errorf("ApiKey after rotation returned %q, %v, want theSecondKey", key, err)
+	}
+	write(" \n")
+	if _, err := creds.ApiKey(ctx); err == nil {
+		t.Error("ApiKey of an empty file returned no error")
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(EnvApiKey, "envKey")
+	t.Setenv("OTHER_API_KEY", "")
+
+	if key, err := EnvCredentials("").ApiKey(ctx); err != nil || key != "envKey" {
+		t.Errorf("ApiKey returned %q, %v, want envKey", key, err)
+	}
+	if _, err := EnvCredentials("OTHER_API_KEY").ApiKey(ctx); err == nil {
+		t.Error("ApiKey of an unset variable returned no error")
+	}
+	os.Setenv("OTHER_API_KEY", "otherKey")
+	if key, err := EnvCredentials("OTHER_API_KEY").ApiKey(ctx); err != nil || key != "otherKey" {
+		t.Errorf("ApiKey returned %q, %v, want otherKey", key, err)
+	}
+}
+
+func TestExecCredentials(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh to run")
+	}
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(path, []byte("firstKey\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds := NewExecCredentials(time.Minute, "sh", "-c", `cat "$0"`, path)
+	now := time.Now()
+	creds.now = func() time.Time { return now }
+
+	if key, err := creds.ApiKey(ctx); err != nil || key != "firstKey" {
+		t.Fatalf("ApiKey returned %q, %v, want firstKey", key, err)
+	}
+	if err := os.WriteFile(path, []byte("secondKey\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := creds.ApiKey(ctx); key != "firstKey" {
+		t.Errorf("ApiKey within the TTL returned %q, want firstKey", key)
+	}
+	now = now.Add(time.Minute)
+	if key, _ := creds.ApiKey(ctx); key != "secondKey" {
+		t.Errorf("ApiKey after the TTL returned %q, want secondKey", key)
+	}
+
+	failing := NewExecCredentials(0, "sh", "-c", "echo vault is sealed >&2; exit 3")
+	if _, err := failing.ApiKey(ctx); err == nil || !strings.Contains(err.Error(), "vault is sealed") {
+		t.Errorf("ApiKey of a failing command returned %v, want its stderr", err)
+	}
+}
+
+func TestNewClientFromEnvKeyFile(t *testing.T) {
+	setup()
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(path, []byte("fileKey\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvApiUser, "anApiUser")
+	t.Setenv(EnvApiKey, "")
+	t.Setenv(EnvApiKeyFile, path)
+
+	var key string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		key = r.FormValue("ApiKey")
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	c, err := NewClientFromEnv(WithBaseURL(server.URL + "/"))
+	if err != nil {
+		t.Fatalf("NewClientFromEnv returned error: %v", err)
+	}
+	if _, err := c.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if key != "fileKey" {
+		t.Errorf("ApiKey sent = %q, want fileKey", key)
+	}
+}
@@ -0,0 +1,116 @@
+package namecheap
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// DecodeResponse reads an API response from r, one element at a time. The
+// CommandResponse element is decoded into result, as by
+// xml.Decoder.DecodeElement, unless result is nil or the response reports
+// errors, in which case it is skipped. When the response reports errors,
+// they are returned along with the response.
+//
+// Interceptors that answer calls themselves, for example from a cache, can
+// use it to decode a stored response into the Invocation.Result of a call.
+func DecodeResponse(r io.Reader, result interface{}) (*ApiResponse, error) {
+	d := xml.NewDecoder(r)
+	var root xml.StartElement
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, errors.New("failed to parse xml from api")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			root = start
+			break
+		}
+	}
+
+	resp := &ApiResponse{}
+	for _, attr := range root.Attr {
+		if attr.Name.Local == "Status" {
+			resp.Status = attr.Value
+		}
+	}
+	if resp.Status == "" {
+		return nil, errors.New("failed to parse xml from api")
+	}
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			if _, ok := tok.(xml.EndElement); ok {
+				break
+			}
+			continue
+		}
+
+		switch start.Name.Local {
+		case "Errors":
+			var errs struct {
+				Errors ApiErrors `xml:"Error"`
+			}
+			err = d.DecodeElement(&errs, &start)
+			resp.Errors = errs.Errors
+		case "Warnings":
+			var warnings struct {
+				Warnings []ApiError `xml:"Warning"`
+			}
+			err = d.DecodeElement(&warnings, &start)
+			resp.Warnings = warnings.Warnings
+		case "RequestedCommand":
+			err = d.DecodeElement(&resp.Command, &start)
+		case "Server":
+			err = d.DecodeElement(&resp.Server, &start)
+		case "GMTTimeDifference":
+			err = d.DecodeElement(&resp.GMTTimeDifference, &start)
+		case "ExecutionTime":
+			err = d.DecodeElement(&resp.ExecutionTime, &start)
+		case "CommandResponse":
+			if result == nil || resp.Status == "ERROR" {
+				err = d.Skip()
+				break
+			}
+			err = d.DecodeElement(result, &start)
+			resp.Result = result
+		default:
+			err = d.Skip()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.Status == "ERROR" {
+		return resp, resp.Errors
+	}
+	return resp, nil
+}
+
+// copyResult copies the result an interceptor returned into the target of
+// the call, unless it already is the target.
+func copyResult(target, result interface{}) error {
+	if target == nil || result == nil || target == result {
+		return nil
+	}
+	dst, src := reflect.ValueOf(target), reflect.ValueOf(result)
+	if dst.Type() != src.Type() || src.IsNil() {
+		return fmt.Errorf("interceptor returned a result of type %T, want %T", result, target)
+	}
+	dst.Elem().Set(src.Elem())
+	return nil
+}
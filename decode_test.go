@@ -0,0 +1,104 @@
+package namecheap
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	var result domainsGetListResponse
+	resp, err := DecodeResponse(strings.NewReader(`<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <Warnings><Warning Number="1">Careful</Warning></Warnings>
+  <RequestedCommand>namecheap.domains.getList</RequestedCommand>
+  <CommandResponse Type="namecheap.domains.getList">
+    <DomainGetListResult><Domain ID="1" Name="example.com" /></DomainGetListResult>
+  </CommandResponse>
+  <Server>WEB1</Server>
+  <GMTTimeDifference>--5:00</GMTTimeDifference>
+  <ExecutionTime>0.01</ExecutionTime>
+</ApiResponse>`), &result)
+	if err != nil {
+		t.Fatalf("DecodeResponse returned error: %v", err)
+	}
+	want := &ApiResponse{
+		Status:  "OK",
+		Command: "namecheap.domains.getList",
+		ResponseMetadata: ResponseMetadata{
+			Warnings:          []ApiError{{Number: 1, Message: "Careful"}},
+			Server:            "WEB1",
+			GMTTimeDifference: "--5:00",
+			ExecutionTime:     "0.01",
+		},
+		Result: &result,
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("DecodeResponse returned %+v, want %+v", resp, want)
+	}
+	if len(result.Domains) != 1 || result.Domains[0].Name != "example.com" {
+		t.Errorf("DecodeResponse decoded result %+v", result)
+	}
+}
+
+func TestDecodeResponseErrors(t *testing.T) {
+	var result domainsGetInfoResponse
+	resp, err := DecodeResponse(strings.NewReader(`<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2030166">Domain is invalid</Error></Errors>
+  <CommandResponse Type="namecheap.domains.getInfo"><DomainGetInfoResult ID="0" /></CommandResponse>
+  <Server>WEB1</Server>
+</ApiResponse>`), &result)
+	if !HasApiErrorNumber(err, 2030166) {
+		t.Errorf("DecodeResponse returned %v, want error 2030166", err)
+	}
+	if resp == nil || resp.Server != "WEB1" || resp.Result != nil {
+		t.Errorf("DecodeResponse returned %+v for an error response", resp)
+	}
+	if result.DomainInfo != nil {
+		t.Errorf("DecodeResponse decoded the CommandResponse of an error response")
+	}
+
+	for _, body := range []string{
+		``,
+		`<ApiResponse xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`,
+		`<ApiResponse Status="OK"><Errors>`,
+	} {
+		if _, err := DecodeResponse(strings.NewReader(body), nil); err == nil {
+			t.Errorf("DecodeResponse of %q returned no error", body)
+		}
+	}
+}
+
+func TestInterceptorResult(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request reached the API despite the short-circuiting interceptor")
+	})
+
+	info := &DomainInfo{Name: "example.com"}
+	var replacement interface{} = &domainsGetInfoResponse{DomainInfo: info}
+	client.Interceptors = []Interceptor{
+		func(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error) {
+			return &ApiResponse{Status: "OK", Result: replacement}, nil
+		},
+	}
+
+	got, err := client.DomainGetInfo("example.com")
+	if err != nil {
+		t.Fatalf("DomainGetInfo returned error: %v", err)
+	}
+	if got != info {
+		t.Errorf("DomainGetInfo returned %+v, want %+v", got, info)
+	}
+
+	replacement = &domainsGetListResponse{}
+	if _, err := client.DomainGetInfo("example.com"); err == nil {
+		t.Errorf("DomainGetInfo returned %v for a result of the wrong type", err)
+	}
+}
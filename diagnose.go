@@ -0,0 +1,179 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// CheckStatus is the outcome of one of the checks of a Diagnosis.
+type CheckStatus int
+
+const (
+	// CheckUnknown means the API did not get as far as checking the value,
+	// because an earlier check failed or the call did not reach it.
+	CheckUnknown CheckStatus = iota
+	// CheckAccepted means the API accepted the value.
+	CheckAccepted
+	// CheckRejected means the API rejected the value.
+	CheckRejected
+)
+
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckUnknown:
+		return "unknown"
+	case CheckAccepted:
+		return "accepted"
+	case CheckRejected:
+		return "rejected"
+	}
+	return "invalid"
+}
+
+// Check is the outcome of the check of one parameter of a Diagnosis, along
+// with the API error that rejected it, if any.
+type Check struct {
+	Status CheckStatus
+	Err    *ApiError
+}
+
+// Diagnosis tells which of the parameters that authenticate a client the
+// API accepts. The API checks them in order, the ApiUser and ApiKey, then
+// the IP of the request, then the UserName, so the checks that follow a
+// rejected one are unknown.
+type Diagnosis struct {
+	ApiUser  Check
+	ApiKey   Check
+	ClientIp Check
+	UserName Check
+
+	// EgressIP is the IP the client presented as its ClientIp, as set or
+	// detected by its IPDetector. It is empty if the client could not
+	// tell.
+	EgressIP string
+
+	// SeenIP is the IP the API saw the request come from, which is the one
+	// that must be whitelisted. The API only reports it when it rejects it.
+	SeenIP string
+}
+
+// OK reports whether the API accepted every parameter.
+func (d *Diagnosis) OK() bool {
+	return d.ApiUser.Status == CheckAccepted && d.ApiKey.Status == CheckAccepted &&
+		d.ClientIp.Status == CheckAccepted && d.UserName.Status == CheckAccepted
+}
+
+// Err returns an error naming the first parameter the API rejected, which
+// wraps the error of the API, or nil if it accepted them all.
+func (d *Diagnosis) Err() error {
+	checks := []struct {
+		name  string
+		check Check
+	}{
+		{"ApiUser", d.ApiUser},
+		{"ApiKey", d.ApiKey},
+		{"ClientIp", d.ClientIp},
+		{"UserName", d.UserName},
+	}
+	for _, c := range checks {
+		if c.check.Status != CheckRejected {
+			continue
+		}
+		if c.name == "ClientIp" && d.SeenIP != "" {
+			return fmt.Errorf("namecheap: request IP %s rejected: %w", d.SeenIP, c.check.Err)
+		}
+		return fmt.Errorf("namecheap: %s rejected: %w", c.name, c.check.Err)
+	}
+	return nil
+}
+
+// diagnosisSteps maps the error numbers that reject the authentication of
+// a request to the check that failed: 0 for the ApiUser, 1 for the ApiKey,
+// 2 for the IP and 3 for the UserName.
+var diagnosisSteps = map[int]int{
+	1010101: 0, // Parameter APIUser is missing
+	1017101: 0, // Parameter ApiUser is disabled or locked
+	1010102: 1, // Parameter APIKey is missing
+	1011102: 1, // API Key is invalid or API access has not been enabled
+	1011150: 2, // Invalid request IP
+	1017150: 2, // Parameter RequestIP is disabled or locked
+	1017105: 2, // Parameter ClientIP is disabled or locked
+	1016103: 3, // Parameter UserName is unauthorized
+	1017103: 3, // Parameter UserName is disabled or locked
+	1019103: 3, // Parameter UserName is not available
+}
+
+// requestIPPattern extracts the IP of the request from the message of
+// error 1011150.
+var requestIPPattern = regexp.MustCompile(`(?i)invalid request IP:?\s*(\S+)`)
+
+// Ping makes a cheap authenticated call, and returns an error naming the
+// parameter the API rejected, if any, or the error of the call if it did
+// not get an answer.
+func (client *Client) Ping(ctx context.Context) error {
+	d, err := client.Diagnose(ctx)
+	if err != nil {
+		return err
+	}
+	return d.Err()
+}
+
+// Diagnose makes a cheap authenticated call, users.getBalances, and tells
+// which of the ApiUser, ApiKey, UserName and client IP the API accepts, for
+// example to check the configuration of a client before its first real
+// operation. The error is only returned when the call did not get an answer
+// that tells, such as transport errors and other API errors.
+func (client *Client) Diagnose(ctx context.Context) (*Diagnosis, error) {
+	var result usersGetBalancesResponse
+	requestInfo := &ApiRequest{
+		command: usersGetBalances,
+		method:  "POST",
+		params:  url.Values{},
+		result:  &result,
+	}
+	_, err := client.do(ctx, requestInfo)
+	// The params hold the ClientIp that was sent once the call is made.
+	egressIP := requestInfo.params.Get("ClientIp")
+	if err == nil {
+		return &Diagnosis{
+			ApiUser:  Check{Status: CheckAccepted},
+			ApiKey:   Check{Status: CheckAccepted},
+			ClientIp: Check{Status: CheckAccepted},
+			UserName: Check{Status: CheckAccepted},
+			EgressIP: egressIP,
+		}, nil
+	}
+
+	var apiErrs ApiErrors
+	if !errors.As(err, &apiErrs) {
+		return nil, err
+	}
+	for i := range apiErrs {
+		apiErr := &apiErrs[i]
+		step, ok := diagnosisSteps[apiErr.Number]
+		if !ok {
+			continue
+		}
+		d := &Diagnosis{EgressIP: egressIP}
+		checks := []*Check{&d.ApiUser, &d.ApiKey, &d.ClientIp, &d.UserName}
+		for _, check := range checks[:step] {
+			check.Status = CheckAccepted
+		}
+		// The API only tells the ApiUser from the ApiKey when one of them
+		// is missing.
+		if step == 1 && apiErr.Number == 1011102 {
+			checks[0].Status = CheckUnknown
+		}
+		checks[step].Status, checks[step].Err = CheckRejected, apiErr
+		if step == 2 {
+			if m := requestIPPattern.FindStringSubmatch(apiErr.Message); m != nil {
+				d.SeenIP = m[1]
+			}
+		}
+		return d, nil
+	}
+	return nil, err
+}
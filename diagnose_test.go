@@ -0,0 +1,111 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var response string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if command := r.FormValue("Command"); command != usersGetBalances {
+			t.Errorf("Command = %s, want %s", command, usersGetBalances)
+		}
+		fmt.Fprint(w, response)
+	})
+	errorResponse := func(number int, message string) string {
+		return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="%d">%s</Error></Errors>
+</ApiResponse>`, number, message)
+	}
+
+	response = `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <CommandResponse Type="namecheap.users.getBalances">
+    <UserGetBalancesResult Currency="USD" AvailableBalance="4932.96" AccountBalance="4932.96" EarnedAmount="381.70" WithdrawableAmount="1243.36" FundsRequiredForAutoRenew="0.00" />
+  </CommandResponse>
+</ApiResponse>`
+	d, err := client.Diagnose(context.Background())
+	if err != nil {
+		t.Fatalf("Diagnose returned error: %v", err)
+	}
+	if !d.OK() || d.Err() != nil || d.EgressIP != "127.0.0.1" {
+		t.Errorf("Diagnose returned %+v, want everything accepted from 127.0.0.1", d)
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping returned error: %v", err)
+	}
+
+	accepted, rejected, unknown := CheckAccepted, CheckRejected, CheckUnknown
+	tests := []struct {
+		number  int
+		message string
+		want    [4]CheckStatus
+		seenIP  string
+	}{
+		{1010101, "Parameter APIUser is missing", [4]CheckStatus{rejected, unknown, unknown, unknown}, ""},
+		{1011102, "API Key is invalid or API access has not been enabled", [4]CheckStatus{unknown, rejected, unknown, unknown}, ""},
+		{1011150, "Invalid request IP: 198.51.100.7", [4]CheckStatus{accepted, accepted, rejected, unknown}, "198.51.100.7"},
+		{1016103, "Parameter UserName is unauthorized", [4]CheckStatus{accepted, accepted, accepted, rejected}, ""},
+	}
+	for _, tt := range tests {
+		response = errorResponse(tt.number, tt.message)
+		d, err := client.Diagnose(context.Background())
+		if err != nil {
+			t.Errorf("Diagnose of error %d returned error: %v", tt.number, err)
+			continue
+		}
+		got := [4]CheckStatus{d.ApiUser.Status, d.ApiKey.Status, d.ClientIp.Status, d.UserName.Status}
+		if got != tt.want || d.SeenIP != tt.seenIP || d.EgressIP != "127.0.0.1" {
+			t.Errorf("Diagnose of error %d returned %v with IPs %q and %q, want %v with 127.0.0.1 and %q", tt.number, got, d.EgressIP, d.SeenIP, tt.want, tt.seenIP)
+		}
+		if d.OK() {
+			t.Errorf("Diagnose of error %d returned OK", tt.number)
+		}
+		err = client.Ping(context.Background())
+		if !HasApiErrorNumber(err, tt.number) {
+			t.Errorf("Ping of error %d returned %v, want it wrapped", tt.number, err)
+		}
+		if tt.seenIP != "" && !strings.Contains(fmt.Sprint(err), tt.seenIP) {
+			t.Errorf("Ping of error %d returned %v, want it to name %s", tt.number, err, tt.seenIP)
+		}
+	}
+
+	// The ClientIp presented is the detected one.
+	client.IPDetector = ipDetectorFunc(func(ctx context.Context) (string, error) {
+		return "203.0.113.4", nil
+	})
+	if d, err := client.Diagnose(context.Background()); err != nil || d.EgressIP != "203.0.113.4" {
+		t.Errorf("Diagnose with an IPDetector returned %+v, %v, want the detected IP", d, err)
+	}
+	client.IPDetector = nil
+
+	// Other errors tell nothing about the credentials.
+	response = errorResponse(2011166, "Something else went wrong")
+	if d, err := client.Diagnose(context.Background()); d != nil || !HasApiErrorNumber(err, 2011166) {
+		t.Errorf("Diagnose of an unrelated error returned %+v, %v", d, err)
+	}
+	client.HttpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}
+	client.RetryPolicy = nil
+	if d, err := client.Diagnose(context.Background()); d != nil || err == nil {
+		t.Errorf("Diagnose of an unreachable API returned %+v, %v", d, err)
+	}
+}
+
+func TestCheckStatusString(t *testing.T) {
+	got := []string{CheckUnknown.String(), CheckAccepted.String(), CheckRejected.String()}
+	if want := []string{"unknown", "accepted", "rejected"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("String = %v, want %v", got, want)
+	}
+}
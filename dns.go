@@ -1,6 +1,7 @@
 package namecheap
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -32,30 +33,58 @@ type DomainDNSSetHostsResult struct {
 	IsSuccess bool   `xml:"IsSuccess,attr"`
 }
 
+// The CommandResponse elements the results of the commands are decoded from.
+type (
+	domainsDNSGetHostsResponse struct {
+		DomainDNSHosts *DomainDNSGetHostsResult `xml:"DomainDNSGetHostsResult"`
+	}
+	domainsDNSSetHostsResponse struct {
+		DomainDNSSetHosts *DomainDNSSetHostsResult `xml:"DomainDNSSetHostsResult"`
+	}
+	domainsDNSSetCustomResponse struct {
+		DomainDNSSetCustom *DomainDNSSetCustomResult `xml:"DomainDNSSetCustomResult"`
+	}
+)
+
 func (client *Client) DomainsDNSGetHosts(sld, tld string) (*DomainDNSGetHostsResult, error) {
+	return client.DomainsDNSGetHostsContext(context.Background(), sld, tld)
+}
+
+// DomainsDNSGetHostsContext is like DomainsDNSGetHosts but takes a context.
+func (client *Client) DomainsDNSGetHostsContext(ctx context.Context, sld, tld string) (*DomainDNSGetHostsResult, error) {
+	var result domainsDNSGetHostsResponse
 	requestInfo := &ApiRequest{
 		command: domainsDNSGetHosts,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 	requestInfo.params.Set("SLD", sld)
 	requestInfo.params.Set("TLD", tld)
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.DomainDNSHosts, nil
+	return result.DomainDNSHosts, nil
 }
 
 func (client *Client) DomainDNSSetHosts(
 	sld, tld string, hosts []DomainDNSHost,
 ) (*DomainDNSSetHostsResult, error) {
+	return client.DomainDNSSetHostsContext(context.Background(), sld, tld, hosts)
+}
+
+// DomainDNSSetHostsContext is like DomainDNSSetHosts but takes a context.
+func (client *Client) DomainDNSSetHostsContext(
+	ctx context.Context, sld, tld string, hosts []DomainDNSHost,
+) (*DomainDNSSetHostsResult, error) {
+	var result domainsDNSSetHostsResponse
 	requestInfo := &ApiRequest{
 		command: domainsDNSSetHosts,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 	requestInfo.params.Set("SLD", sld)
 	requestInfo.params.Set("TLD", tld)
@@ -71,11 +100,10 @@ func (client *Client) DomainDNSSetHosts(
 		requestInfo.params.Set(fmt.Sprintf("TTL%v", i+1), strconv.Itoa(h.TTL))
 	}
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
-	return resp.DomainDNSSetHosts, nil
+	return result.DomainDNSSetHosts, nil
 }
 
 type DomainDNSSetCustomResult struct {
@@ -84,18 +112,24 @@ type DomainDNSSetCustomResult struct {
 }
 
 func (client *Client) DomainDNSSetCustom(sld, tld, nameservers string) (*DomainDNSSetCustomResult, error) {
+	return client.DomainDNSSetCustomContext(context.Background(), sld, tld, nameservers)
+}
+
+// DomainDNSSetCustomContext is like DomainDNSSetCustom but takes a context.
+func (client *Client) DomainDNSSetCustomContext(ctx context.Context, sld, tld, nameservers string) (*DomainDNSSetCustomResult, error) {
+	var result domainsDNSSetCustomResponse
 	requestInfo := &ApiRequest{
 		command: domainsDNSSetCustom,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 	requestInfo.params.Set("SLD", sld)
 	requestInfo.params.Set("TLD", tld)
 	requestInfo.params.Set("Nameservers", nameservers)
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
-	return resp.DomainDNSSetCustom, nil
+	return result.DomainDNSSetCustom, nil
 }
@@ -1,7 +1,8 @@
 package namecheap
 
 import (
-	"errors"
+	"context"
+	"encoding/xml"
 	"net/url"
 	"strconv"
 	"strings"
@@ -102,88 +103,234 @@ type DomainCreateOption struct {
 	AddFreeWhoisguard bool
 	WGEnabled         bool
 	Nameservers       []string
+
+	// Contacts, if set, are the contacts of the new domain, instead of the
+	// Registrant of the client.
+	Contacts *Registrant
+}
+
+type DomainSetContactsOption struct {
+	// Contacts, if set, are the new contacts of the domain, instead of the
+	// Registrant of the client.
+	Contacts *Registrant
 }
 
+// The CommandResponse elements the results of the commands are decoded from.
+type (
+	domainsGetListResponse struct {
+		Domains    []DomainGetListResult `xml:"DomainGetListResult>Domain"`
+		TotalItems int                   `xml:"Paging>TotalItems"`
+	}
+	domainsGetInfoResponse struct {
+		DomainInfo *DomainInfo `xml:"DomainGetInfoResult"`
+	}
+	domainsCheckResponse struct {
+		DomainsCheck []DomainCheckResult `xml:"DomainCheckResult"`
+	}
+	domainsTLDListResponse struct {
+		TLDList []TLDListResult `xml:"Tlds>Tld"`
+	}
+	domainsCreateResponse struct {
+		DomainCreate *DomainCreateResult `xml:"DomainCreateResult"`
+	}
+	domainsRenewResponse struct {
+		DomainRenew *DomainRenewResult `xml:"DomainRenewResult"`
+	}
+	domainsSetContactsResponse struct {
+		DomainSetContacts *DomainSetContactsResult `xml:"DomainSetContactResult"`
+	}
+)
+
 func (client *Client) DomainsGetList() ([]DomainGetListResult, error) {
+	return client.DomainsGetListContext(context.Background())
+}
+
+// DomainsGetListContext is like DomainsGetList but takes a context.
+func (client *Client) DomainsGetListContext(ctx context.Context) ([]DomainGetListResult, error) {
+	var result domainsGetListResponse
 	requestInfo := &ApiRequest{
 		command: domainsGetList,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.Domains, nil
+	return result.Domains, nil
+}
+
+// domainsGetListPage returns a page of the domains of the account, along
+// with the number of domains of all the pages. The page is always asked to
+// the API, never taken from the Cache of the client, since it is used to
+// find out where domains moved.
+func (client *Client) domainsGetListPage(ctx context.Context, page, pageSize int) ([]DomainGetListResult, int, error) {
+	var result domainsGetListResponse
+	requestInfo := &ApiRequest{
+		command: domainsGetList,
+		method:  "POST",
+		params:  url.Values{},
+		result:  &result,
+		noCache: true,
+	}
+	requestInfo.params.Set("Page", strconv.Itoa(page))
+	requestInfo.params.Set("PageSize", strconv.Itoa(pageSize))
+
+	if _, err := client.do(ctx, requestInfo); err != nil {
+		return nil, 0, err
+	}
+
+	return result.Domains, result.TotalItems, nil
+}
+
+// DomainsGetListFunc calls fn with every domain of the response, as soon as
+// it is decoded, so that long lists are never held in memory at once. It
+// stops at the first error returned by fn, and returns it.
+func (client *Client) DomainsGetListFunc(fn func(DomainGetListResult) error) error {
+	return client.DomainsGetListFuncContext(context.Background(), fn)
+}
+
+// DomainsGetListFuncContext is like DomainsGetListFunc but takes a context.
+func (client *Client) DomainsGetListFuncContext(ctx context.Context, fn func(DomainGetListResult) error) error {
+	requestInfo := &ApiRequest{
+		command: domainsGetList,
+		method:  "POST",
+		params:  url.Values{},
+		result:  &domainsGetListStream{fn: fn},
+	}
+
+	_, err := client.do(ctx, requestInfo)
+	return err
+}
+
+// domainsGetListStream decodes the CommandResponse element of
+// domains.getList one domain at a time.
+type domainsGetListStream struct {
+	fn func(DomainGetListResult) error
+}
+
+func (s *domainsGetListStream) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "DomainGetListResult":
+				// Descend into the list.
+			case "Domain":
+				var domain DomainGetListResult
+				if err := d.DecodeElement(&domain, &t); err != nil {
+					return err
+				}
+				if err := s.fn(domain); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if t.Name == start.Name {
+				return nil
+			}
+		}
+	}
 }
 
 func (client *Client) DomainGetInfo(domainName string) (*DomainInfo, error) {
+	return client.DomainGetInfoContext(context.Background(), domainName)
+}
+
+// DomainGetInfoContext is like DomainGetInfo but takes a context.
+func (client *Client) DomainGetInfoContext(ctx context.Context, domainName string) (*DomainInfo, error) {
+	var result domainsGetInfoResponse
 	requestInfo := &ApiRequest{
 		command: domainsGetInfo,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
 	requestInfo.params.Set("DomainName", domainName)
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	if resp.DomainInfo != nil && strings.EqualFold(resp.DomainInfo.Whoisguard.RawEnabled, "true") {
-		resp.DomainInfo.Whoisguard.Enabled = true
+	if result.DomainInfo != nil && strings.EqualFold(result.DomainInfo.Whoisguard.RawEnabled, "true") {
+		result.DomainInfo.Whoisguard.Enabled = true
 	}
-	return resp.DomainInfo, nil
+	return result.DomainInfo, nil
 }
 
 func (client *Client) DomainsCheck(domainNames ...string) ([]DomainCheckResult, error) {
+	return client.DomainsCheckContext(context.Background(), domainNames...)
+}
+
+// DomainsCheckContext is like DomainsCheck but takes a context.
+func (client *Client) DomainsCheckContext(ctx context.Context, domainNames ...string) ([]DomainCheckResult, error) {
+	var result domainsCheckResponse
 	requestInfo := &ApiRequest{
 		command: domainsCheck,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
 	requestInfo.params.Set("DomainList", strings.Join(domainNames, ","))
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.DomainsCheck, nil
+	return result.DomainsCheck, nil
 }
 
 func (client *Client) DomainsTLDList() ([]TLDListResult, error) {
+	return client.DomainsTLDListContext(context.Background())
+}
+
+// DomainsTLDListContext is like DomainsTLDList but takes a context.
+func (client *Client) DomainsTLDListContext(ctx context.Context) ([]TLDListResult, error) {
+	var result domainsTLDListResponse
 	requestInfo := &ApiRequest{
 		command: domainsTLDList,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.TLDList, nil
+	return result.TLDList, nil
 }
 
 func (client *Client) DomainCreate(domainName string, years int, options ...DomainCreateOption) (*DomainCreateResult, error) {
-	if client.Registrant == nil {
-		return nil, errors.New("Registrant information on client cannot be empty")
-	}
+	return client.DomainCreateContext(context.Background(), domainName, years, options...)
+}
 
+// DomainCreateContext is like DomainCreate but takes a context.
+func (client *Client) DomainCreateContext(ctx context.Context, domainName string, years int, options ...DomainCreateOption) (*DomainCreateResult, error) {
+	var result domainsCreateResponse
 	requestInfo := &ApiRequest{
 		command: domainsCreate,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
 	requestInfo.params.Set("DomainName", domainName)
 	requestInfo.params.Set("Years", strconv.Itoa(years))
+	var contacts []*Registrant
 	for _, opt := range options {
+		contacts = append(contacts, opt.Contacts)
 		if opt.AddFreeWhoisguard {
 			requestInfo.params.Set("AddFreeWhoisguard", "yes")
 		}
@@ -194,50 +341,74 @@ func (client *Client) DomainCreate(domainName string, years int, options ...Doma
 			requestInfo.params.Set("Nameservers", strings.Join(opt.Nameservers, ","))
 		}
 	}
-	if err := client.Registrant.addValues(requestInfo.params); err != nil {
+	reg, err := client.contacts(contacts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := reg.addValues(requestInfo.params); err != nil {
 		return nil, err
 	}
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.DomainCreate, nil
+	return result.DomainCreate, nil
 }
 
 func (client *Client) DomainRenew(domainName string, years int) (*DomainRenewResult, error) {
+	return client.DomainRenewContext(context.Background(), domainName, years)
+}
+
+// DomainRenewContext is like DomainRenew but takes a context.
+func (client *Client) DomainRenewContext(ctx context.Context, domainName string, years int) (*DomainRenewResult, error) {
+	var result domainsRenewResponse
 	requestInfo := &ApiRequest{
 		command: domainsRenew,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 	requestInfo.params.Set("DomainName", domainName)
 	requestInfo.params.Set("Years", strconv.Itoa(years))
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.DomainRenew, nil
+	return result.DomainRenew, nil
 }
 
-func (client *Client) DomainSetContacts(domainName string) (*DomainSetContactsResult, error) {
+func (client *Client) DomainSetContacts(domainName string, options ...DomainSetContactsOption) (*DomainSetContactsResult, error) {
+	return client.DomainSetContactsContext(context.Background(), domainName, options...)
+}
+
+// DomainSetContactsContext is like DomainSetContacts but takes a context.
+func (client *Client) DomainSetContactsContext(ctx context.Context, domainName string, options ...DomainSetContactsOption) (*DomainSetContactsResult, error) {
+	var contacts []*Registrant
+	for _, opt := range options {
+		contacts = append(contacts, opt.Contacts)
+	}
+	reg, err := client.contacts(contacts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result domainsSetContactsResponse
 	requestInfo := &ApiRequest{
 		command: domainsSetContacts,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 	requestInfo.params.Set("DomainName", domainName)
-	if err := client.Registrant.addValues(requestInfo.params); err != nil {
+	if err := reg.addValues(requestInfo.params); err != nil {
 		return nil, err
 	}
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.DomainSetContacts, nil
+	return result.DomainSetContacts, nil
 }
@@ -1,10 +1,13 @@
 package namecheap
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -429,3 +432,126 @@ func TestDomainSetContacts(t *testing.T) {
 		t.Errorf("DomainSetContactsResult returned %+v, want %+v", result, want)
 	}
 }
+
+func TestDomainPerCallContacts(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	owners := map[string]string{}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		owners[r.FormValue("DomainName")] = r.FormValue("RegistrantFirstName") + "/" + r.FormValue("AdminEmailAddress")
+		mu.Unlock()
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ApiResponse xmlns="http://api.namecheap.com/xml.response" Status="OK">
+  <CommandResponse Type="namecheap.domains.setContacts">
+    <DomainSetContactResult Domain="domain1.com" IsSuccess="true" />
+  </CommandResponse>
+</ApiResponse>`)
+	})
+
+	if _, err := client.DomainSetContacts("domain0.com"); err == nil {
+		t.Error("DomainSetContacts without contacts returned no error")
+	}
+	if _, err := client.DomainCreate("domain0.com", 1); err == nil {
+		t.Error("DomainCreate without contacts returned no error")
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("Owner%d", i)
+			reg := NewRegistrant(
+				name, "Smith",
+				"8939 S.cross Blvd", "",
+				"CA", "CA", "90045", "US",
+				"+1.6613102107", strings.ToLower(name)+"@example.com",
+			)
+			domain := fmt.Sprintf("domain%d.com", i)
+			if _, err := client.DomainSetContacts(domain, DomainSetContactsOption{Contacts: reg}); err != nil {
+				t.Errorf("DomainSetContacts returned error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i <= 5; i++ {
+		want := fmt.Sprintf("Owner%d/owner%d@example.com", i, i)
+		if got := owners[fmt.Sprintf("domain%d.com", i)]; got != want {
+			t.Errorf("domain%d.com was sent contacts %q, want %q", i, got, want)
+		}
+	}
+
+	// Contacts given with the call take precedence over the client's.
+	client.NewRegistrant(
+		"Default", "Smith",
+		"8939 S.cross Blvd", "",
+		"CA", "CA", "90045", "US",
+		"+1.6613102107", "default@example.com",
+	)
+	reg := NewRegistrant(
+		"Other", "Smith",
+		"8939 S.cross Blvd", "",
+		"CA", "CA", "90045", "US",
+		"+1.6613102107", "other@example.com",
+	)
+	if _, err := client.DomainCreate("domain6.com", 1, DomainCreateOption{WGEnabled: true, Contacts: reg}); err != nil {
+		t.Fatalf("DomainCreate returned error: %v", err)
+	}
+	if _, err := client.DomainSetContacts("domain7.com"); err != nil {
+		t.Fatalf("DomainSetContacts returned error: %v", err)
+	}
+	if got, want := owners["domain6.com"], "Other/other@example.com"; got != want {
+		t.Errorf("DomainCreate sent contacts %q, want %q", got, want)
+	}
+	if got, want := owners["domain7.com"], "Default/default@example.com"; got != want {
+		t.Errorf("DomainSetContacts sent contacts %q, want %q", got, want)
+	}
+}
+
+func TestDomainsGetListFunc(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <Errors />
+  <RequestedCommand>namecheap.domains.getList</RequestedCommand>
+  <CommandResponse Type="namecheap.domains.getList">
+    <DomainGetListResult>
+      <Domain ID="1" Name="a.com" />
+      <Domain ID="2" Name="b.com" />
+      <Domain ID="3" Name="c.com" />
+    </DomainGetListResult>
+    <Paging><TotalItems>3</TotalItems></Paging>
+  </CommandResponse>
+  <Server>WEB1-SANDBOX1</Server>
+</ApiResponse>`)
+	})
+
+	var names []string
+	err := client.DomainsGetListFunc(func(d DomainGetListResult) error {
+		names = append(names, d.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DomainsGetListFunc returned error: %v", err)
+	}
+	if want := []string{"a.com", "b.com", "c.com"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("DomainsGetListFunc visited %v, want %v", names, want)
+	}
+
+	stop := errors.New("stop")
+	names = nil
+	err = client.DomainsGetListFunc(func(d DomainGetListResult) error {
+		names = append(names, d.Name)
+		return stop
+	})
+	if !errors.Is(err, stop) || len(names) != 1 {
+		t.Errorf("DomainsGetListFunc returned %v after visiting %v, want it to stop at the first domain", err, names)
+	}
+}
@@ -0,0 +1,83 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// ErrDryRun is returned by the calls of mutating commands made by a client
+// in dry-run mode, which are recorded instead of being sent.
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// DryRunRequest is a request that a client in dry-run mode built but did
+// not send.
+type DryRunRequest struct {
+	Command string
+	Method  string
+	URL     string
+	Header  http.Header
+
+	// Params holds the parameters of the request, with the values of
+	// secret ones, such as the ApiKey, redacted.
+	Params url.Values
+}
+
+// DryRunRecorder receives the requests of a client in dry-run mode.
+type DryRunRecorder interface {
+	RecordDryRun(req DryRunRequest)
+}
+
+// DryRunLog is a DryRunRecorder that keeps the requests in memory. It is
+// safe for concurrent use.
+type DryRunLog struct {
+	mu       sync.Mutex
+	requests []DryRunRequest
+}
+
+// RecordDryRun implements DryRunRecorder.
+func (l *DryRunLog) RecordDryRun(req DryRunRequest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.requests = append(l.requests, req)
+}
+
+// Requests returns the requests recorded so far, in order.
+func (l *DryRunLog) Requests() []DryRunRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]DryRunRequest(nil), l.requests...)
+}
+
+// dryRun builds request as it would be sent and records it in the client's
+// DryRun recorder.
+func (client *Client) dryRun(ctx context.Context, request *ApiRequest) error {
+	req, err := client.makeRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	p, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+
+	client.DryRun.RecordDryRun(DryRunRequest{
+		Command: request.command,
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Header:  req.Header.Clone(),
+		Params:  sanitizeParams(p),
+	})
+	return ErrDryRun
+}
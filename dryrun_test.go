@@ -0,0 +1,67 @@
+package namecheap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var sent []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.FormValue("Command"))
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	log := new(DryRunLog)
+	client.DryRun = log
+	client.UserAgent = "dry-run-test"
+
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if _, err := client.DomainRenew("example.com", 1); !errors.Is(err, ErrDryRun) {
+		t.Errorf("DomainRenew returned %v, want ErrDryRun", err)
+	}
+	if _, err := client.SslActivate(SslActivateParams{CertificateId: 1, Csr: "-----BEGIN CERTIFICATE REQUEST-----"}); !errors.Is(err, ErrDryRun) {
+		t.Errorf("SslActivate returned %v, want ErrDryRun", err)
+	}
+	if err := client.WhoisguardDisable(1); !errors.Is(err, ErrDryRun) {
+		t.Errorf("WhoisguardDisable returned %v, want ErrDryRun", err)
+	}
+	if _, err := client.DomainCreate("example.com", 1); err == nil || errors.Is(err, ErrDryRun) {
+		t.Errorf("DomainCreate without a registrant returned %v, want a validation error", err)
+	}
+
+	if len(sent) != 1 || sent[0] != domainsGetList {
+		t.Errorf("Commands sent = %v, want only %v", sent, domainsGetList)
+	}
+
+	requests := log.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("Recorded %d requests, want 3", len(requests))
+	}
+	renew := requests[0]
+	if renew.Command != domainsRenew || renew.Method != "POST" || renew.URL != client.BaseURL {
+		t.Errorf("Recorded request %+v", renew)
+	}
+	if renew.Params.Get("DomainName") != "example.com" || renew.Params.Get("UserName") != "anUser" || renew.Params.Get("Command") != domainsRenew {
+		t.Errorf("Recorded params %v", renew.Params)
+	}
+	if renew.Header.Get("User-Agent") != "dry-run-test" {
+		t.Errorf("Recorded header %v", renew.Header)
+	}
+	for _, req := range requests {
+		if key := req.Params.Get("ApiKey"); key != redacted {
+			t.Errorf("Recorded ApiKey %q, want it redacted", key)
+		}
+	}
+	if csr := requests[1].Params.Get("CSR"); strings.Contains(csr, "BEGIN") {
+		t.Errorf("Recorded CSR %q, want it redacted", csr)
+	}
+}
@@ -0,0 +1,117 @@
+package namecheap
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrEgressUnsupported is returned by every call of a client given
+// WithProxy or WithLocalAddr whose HTTP client cannot be configured to use
+// them, so that its requests never leave through another route.
+var ErrEgressUnsupported = errors.New("proxy and local address cannot be set on the transport of the HTTP client")
+
+// WithProxy sends the requests of the client through the proxy at
+// proxyURL: an HTTP CONNECT proxy for http:// and https:// URLs, or a SOCKS5
+// one for socks5:// URLs. Credentials can be given in the user info of the
+// URL.
+//
+// Since the API then sees the address of the proxy, the client detects its
+// ClientIp through the proxy, by asking DefaultIPEchoURL, unless a
+// WithClientIP or WithIPDetector option follows this one.
+//
+// The proxy is set on a copy of the transport of the HTTP client once every
+// option has run, so WithHTTPClient can come before or after this option.
+// If that transport is not an *http.Transport, every call of the client
+// fails with ErrEgressUnsupported.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(client *Client) {
+		client.proxy = proxyURL
+		client.ipFollowsEgress = true
+	}
+}
+
+// WithLocalAddr sends the requests of the client from the local address
+// ip, such as the address of the interface routed through the NAT gateway
+// whose address is whitelisted.
+//
+// If ip is a public address and no proxy is used, it is the ClientIp sent
+// to the API. Otherwise the client detects its ClientIp through the same
+// route as its requests, by asking DefaultIPEchoURL. Either way, a
+// WithClientIP or WithIPDetector option following this one takes
+// precedence. Like the proxy of WithProxy, the local address is set once
+// every option has run, and calls fail with ErrEgressUnsupported if it
+// cannot be.
+func WithLocalAddr(ip net.IP) Option {
+	return func(client *Client) {
+		client.localAddr = ip
+		client.ipFollowsEgress = true
+	}
+}
+
+// applyEgress configures the HTTP client of the client with the proxy and
+// local address given by the options, and makes its ClientIp follow them.
+// It is called by NewClient once every option has run.
+func (client *Client) applyEgress() {
+	if client.proxy == nil && client.localAddr == nil {
+		return
+	}
+	t, err := client.cloneTransport()
+	if err != nil {
+		client.egressErr = err
+		return
+	}
+	if client.proxy != nil {
+		t.Proxy = http.ProxyURL(client.proxy)
+	}
+	if client.localAddr != nil {
+		// The same settings as the dialer of http.DefaultTransport.
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			LocalAddr: &net.TCPAddr{IP: client.localAddr},
+		}
+		t.DialContext = dialer.DialContext
+	}
+	if client.ipFollowsEgress {
+		client.followEgress()
+	}
+}
+
+// cloneTransport replaces the HTTP client of the client by a copy with its
+// own copy of the transport, so that configuring it does not affect
+// http.DefaultClient or HTTP clients shared with other code, and returns
+// that transport.
+func (client *Client) cloneTransport() (*http.Transport, error) {
+	httpClient := *http.DefaultClient
+	if client.HttpClient != nil {
+		httpClient = *client.HttpClient
+	}
+	rt := httpClient.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("%w: it is a %T", ErrEgressUnsupported, rt)
+	}
+	t = t.Clone()
+	httpClient.Transport = t
+	client.HttpClient = &httpClient
+	return t, nil
+}
+
+// followEgress keeps the ClientIp of the client consistent with the route
+// its requests take to the API.
+func (client *Client) followEgress() {
+	if client.proxy == nil && client.localAddr != nil &&
+		client.localAddr.IsGlobalUnicast() && !client.localAddr.IsPrivate() {
+		client.ClientIp, client.IPDetector = client.localAddr.String(), nil
+		return
+	}
+	echo := &HTTPEchoDetector{URL: DefaultIPEchoURL, HttpClient: client.HttpClient}
+	client.IPDetector = NewCachedIPDetector(echo, DefaultIPDetectionTTL)
+}
@@ -0,0 +1,106 @@
+package namecheap
+
+import (
+	"errors"
+	"strings"
+)
+
+// Sentinel errors for common API error numbers. They can be matched against
+// the errors returned by Client methods with errors.Is:
+//
+//	if errors.Is(err, namecheap.ErrDomainNotOwned) {
+//		// ...
+//	}
+//
+// A single response may carry several errors, so more than one sentinel can
+// match the same error value.
+var (
+	ErrInvalidCredentials = errors.New("invalid API credentials")
+	ErrIPNotWhitelisted   = errors.New("client IP is not whitelisted")
+	ErrDomainNotFound     = errors.New("domain not found")
+	ErrDomainNotOwned     = errors.New("domain is not associated with the account")
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrDomainUnavailable  = errors.New("domain is not available")
+	ErrRateLimited        = errors.New("too many requests")
+)
+
+// errorsByNumber maps the error numbers documented by Namecheap to the
+// sentinel they belong to.
+var errorsByNumber = map[int]error{
+	1010101: ErrInvalidCredentials, // Parameter APIUser is missing
+	1010102: ErrInvalidCredentials, // Parameter APIKey is missing
+	1011102: ErrInvalidCredentials, // API Key is invalid or API access has not been enabled
+	1017101: ErrInvalidCredentials, // Parameter ApiUser is disabled or locked
+	1016103: ErrInvalidCredentials, // Parameter UserName is unauthorized
+	1017103: ErrInvalidCredentials, // Parameter UserName is disabled or locked
+	1019103: ErrInvalidCredentials, // Parameter UserName is not available
+
+	1011150: ErrIPNotWhitelisted, // Invalid request IP
+	1017150: ErrIPNotWhitelisted, // Parameter RequestIP is disabled or locked
+	1017105: ErrIPNotWhitelisted, // Parameter ClientIP is disabled or locked
+
+	2019166: ErrDomainNotFound,    // Domain not found
+	2016166: ErrDomainNotOwned,    // Domain is not associated with your account
+	3019166: ErrDomainUnavailable, // Domain not available
+	4019166: ErrDomainUnavailable, // Domain not available
+	500000:  ErrRateLimited,       // Too many requests
+}
+
+// errorsByMessage maps phrases of error messages to the sentinel they
+// belong to, for the errors the API reports under a generic number, such as
+// 2528166 (Order creation failed). Phrases are matched case-insensitively.
+var errorsByMessage = map[string]error{
+	"insufficient funds": ErrInsufficientFunds,
+}
+
+// Is reports whether err matches target, which is either one of the
+// sentinel errors of this package, matched by the Number of err or for some
+// of them by its Message, or an *ApiError with the same Number.
+func (err *ApiError) Is(target error) bool {
+	if t, ok := target.(*ApiError); ok {
+		return t.Number == err.Number
+	}
+	if sentinel, ok := errorsByNumber[err.Number]; ok && sentinel == target {
+		return true
+	}
+	message := strings.ToLower(err.Message)
+	for phrase, sentinel := range errorsByMessage {
+		if sentinel == target && strings.Contains(message, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns every ApiError of errs, so that errors.Is and errors.As
+// look at each of them.
+func (errs ApiErrors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for i := range errs {
+		unwrapped[i] = &errs[i]
+	}
+	return unwrapped
+}
+
+// ApiErrorNumbers returns the numbers of the API errors contained in err.
+func ApiErrorNumbers(err error) []int {
+	var errs ApiErrors
+	if errors.As(err, &errs) {
+		numbers := make([]int, len(errs))
+		for i, apiError := range errs {
+			numbers[i] = apiError.Number
+		}
+		return numbers
+	}
+
+	var apiError *ApiError
+	if errors.As(err, &apiError) {
+		return []int{apiError.Number}
+	}
+	return nil
+}
+
+// HasApiErrorNumber reports whether err contains an API error with number.
+func HasApiErrorNumber(err error, number int) bool {
+	return errors.Is(err, &ApiError{Number: number})
+}
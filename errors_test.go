@@ -0,0 +1,78 @@
+package namecheap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestApiErrorsIs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors>
+    <Error Number="1011150">Invalid request IP: 10.0.0.1</Error>
+    <Error Number="2016166">Domain is not associated with your account</Error>
+  </Errors>
+  <RequestedCommand>namecheap.domains.getInfo</RequestedCommand>
+</ApiResponse>`)
+	})
+
+	_, err := client.DomainGetInfo("example.com")
+	if err == nil {
+		t.Fatal("Expected error for error response")
+	}
+
+	for _, target := range []error{ErrIPNotWhitelisted, ErrDomainNotOwned} {
+		if !errors.Is(err, target) {
+			t.Errorf("errors.Is(%v, %v) = false, want true", err, target)
+		}
+	}
+	for _, target := range []error{ErrInvalidCredentials, ErrDomainNotFound, ErrRateLimited} {
+		if errors.Is(err, target) {
+			t.Errorf("errors.Is(%v, %v) = true, want false", err, target)
+		}
+	}
+
+	var apiError *ApiError
+	if !errors.As(err, &apiError) || apiError.Number != 1011150 {
+		t.Errorf("errors.As returned %+v, want error 1011150", apiError)
+	}
+
+	if !HasApiErrorNumber(err, 2016166) || HasApiErrorNumber(err, 2019166) {
+		t.Errorf("HasApiErrorNumber did not match the numbers of %v", err)
+	}
+	if numbers, want := ApiErrorNumbers(err), []int{1011150, 2016166}; !reflect.DeepEqual(numbers, want) {
+		t.Errorf("ApiErrorNumbers returned %v, want %v", numbers, want)
+	}
+}
+
+func TestApiErrorNumbers(t *testing.T) {
+	wrapped := fmt.Errorf("renewing: %w", &ApiError{Number: 2019166, Message: "Domain not found"})
+	if numbers, want := ApiErrorNumbers(wrapped), []int{2019166}; !reflect.DeepEqual(numbers, want) {
+		t.Errorf("ApiErrorNumbers returned %v, want %v", numbers, want)
+	}
+	if !errors.Is(wrapped, ErrDomainNotFound) {
+		t.Errorf("errors.Is(%v, ErrDomainNotFound) = false, want true", wrapped)
+	}
+
+	if numbers := ApiErrorNumbers(errors.New("boom")); numbers != nil {
+		t.Errorf("ApiErrorNumbers returned %v for a non-API error, want nil", numbers)
+	}
+}
+
+func TestApiErrorIsInsufficientFunds(t *testing.T) {
+	funds := &ApiError{Number: 2528166, Message: "Order creation failed: Insufficient funds"}
+	if !errors.Is(ApiErrors{*funds}, ErrInsufficientFunds) {
+		t.Errorf("errors.Is(%v, ErrInsufficientFunds) = false, want true", funds)
+	}
+	other := &ApiError{Number: 2528166, Message: "Order creation failed"}
+	if errors.Is(ApiErrors{*other}, ErrInsufficientFunds) {
+		t.Errorf("errors.Is(%v, ErrInsufficientFunds) = true, want false", other)
+	}
+}
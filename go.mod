@@ -0,0 +1,3 @@
+module github.com/billputer/go-namecheap
+
+go 1.21
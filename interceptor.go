@@ -0,0 +1,61 @@
+package namecheap
+
+import (
+	"context"
+	"net/url"
+)
+
+// redacted replaces secret parameter values wherever params leave the
+// HTTP request itself.
+const redacted = "REDACTED"
+
+// Invocation describes an API call as it passes through the interceptors
+// of a Client.
+type Invocation struct {
+	// Command is the API command, such as "namecheap.domains.getList".
+	Command string
+
+	// Params holds every parameter of the call, including the
+	// authentication ones. The ApiKey is always redacted: the real key is
+	// only added to the HTTP request. Changes made to Params by an
+	// interceptor are sent to the API.
+	Params url.Values
+
+	// StatusCode is the HTTP status code of the last attempt. It is zero
+	// until the call has been sent, or if no response was received.
+	StatusCode int
+
+	// Result is a pointer to the value the CommandResponse element of the
+	// response is decoded into. Its type is specific to the command. An
+	// interceptor that answers the call itself fills it, for example with
+	// DecodeResponse, and returns it as the ApiResponse.Result.
+	Result interface{}
+
+	// kind is the kind of the command, as declared or known.
+	kind CommandKind
+
+	// noCache tells the Cache of the client to neither answer the call nor
+	// keep its response.
+	noCache bool
+}
+
+// Invoker performs the API call described by inv.
+type Invoker func(ctx context.Context, inv *Invocation) (*ApiResponse, error)
+
+// Interceptor wraps an API call. It may inspect or modify inv before
+// passing it on to next, inspect or replace what next returns, or return
+// a response or an error of its own without calling next at all.
+type Interceptor func(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error)
+
+// intercept runs inv through the interceptors of the client, ending with
+// final.
+func (client *Client) intercept(ctx context.Context, inv *Invocation, final Invoker) (*ApiResponse, error) {
+	next := final
+	for i := len(client.Interceptors) - 1; i >= 0; i-- {
+		interceptor, invoker := client.Interceptors[i], next
+		next = func(ctx context.Context, inv *Invocation) (*ApiResponse, error) {
+			return interceptor(ctx, inv, invoker)
+		}
+	}
+	return next(ctx, inv)
+}
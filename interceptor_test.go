@@ -0,0 +1,114 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterceptorsOrderAndParams(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		correctParams := fillDefaultParams(url.Values{})
+		correctParams.Set("Command", "namecheap.domains.getInfo")
+		correctParams.Set("DomainName", "example.com")
+		correctParams.Set("Extra", "added")
+		testBody(t, r, correctParams)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	var calls []string
+	client.Interceptors = []Interceptor{
+		func(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error) {
+			calls = append(calls, "outer")
+			if inv.Command != domainsGetInfo {
+				t.Errorf("Command = %v, want %v", inv.Command, domainsGetInfo)
+			}
+			if key := inv.Params.Get("ApiKey"); key != redacted {
+				t.Errorf("ApiKey = %v, want it redacted", key)
+			}
+			if user := inv.Params.Get("UserName"); user != "anUser" {
+				t.Errorf("UserName = %v, want %v", user, "anUser")
+			}
+			resp, err := next(ctx, inv)
+			calls = append(calls, "outer done")
+			if inv.StatusCode != http.StatusOK {
+				t.Errorf("StatusCode = %v, want %v", inv.StatusCode, http.StatusOK)
+			}
+			if key := inv.Params.Get("ApiKey"); key != redacted {
+				t.Errorf("ApiKey = %v after the call, want it redacted", key)
+			}
+			return resp, err
+		},
+		func(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error) {
+			calls = append(calls, "inner")
+			inv.Params.Set("Extra", "added")
+			return next(ctx, inv)
+		},
+	}
+
+	if _, err := client.DomainGetInfo("example.com"); err != nil {
+		t.Fatalf("DomainGetInfo returned error: %v", err)
+	}
+	if want := []string{"outer", "inner", "outer done"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Interceptors ran as %v, want %v", calls, want)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request reached the API despite the short-circuiting interceptor")
+	})
+
+	want := []DomainGetListResult{{ID: 1, Name: "example.com"}}
+	client.Interceptors = []Interceptor{
+		func(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error) {
+			return DecodeResponse(strings.NewReader(`<ApiResponse Status="OK">
+  <CommandResponse><DomainGetListResult><Domain ID="1" Name="example.com" /></DomainGetListResult></CommandResponse>
+</ApiResponse>`), inv.Result)
+		},
+	}
+
+	domains, err := client.DomainsGetList()
+	if err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if !reflect.DeepEqual(domains, want) {
+		t.Errorf("DomainsGetList returned %+v, want %+v", domains, want)
+	}
+}
+
+func TestInterceptorSeesErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2019166">Domain not found</Error></Errors>
+</ApiResponse>`)
+	})
+
+	var seen error
+	client.Interceptors = []Interceptor{
+		func(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error) {
+			resp, err := next(ctx, inv)
+			seen = err
+			return resp, err
+		},
+	}
+
+	_, err := client.DomainGetInfo("example.com")
+	if !errors.Is(err, ErrDomainNotFound) || !errors.Is(seen, ErrDomainNotFound) {
+		t.Errorf("Interceptor saw %v and call returned %v, want ErrDomainNotFound for both", seen, err)
+	}
+}
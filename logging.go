@@ -0,0 +1,57 @@
+package namecheap
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// sensitiveParams are the parameters whose values never leave the HTTP
+// request. Any parameter with "Password" in its name is treated the same.
+var sensitiveParams = map[string]bool{
+	"ApiKey":    true,
+	"CSR":       true,
+	"EPPCode":   true,
+	"ResetCode": true,
+}
+
+// sanitizeParams returns a copy of p with the values of secret parameters
+// redacted.
+func sanitizeParams(p url.Values) url.Values {
+	sanitized := make(url.Values, len(p))
+	for k, v := range p {
+		if sensitiveParams[k] || strings.Contains(strings.ToLower(k), "password") {
+			sanitized[k] = []string{redacted}
+			continue
+		}
+		sanitized[k] = append([]string(nil), v...)
+	}
+	return sanitized
+}
+
+// logCall logs a finished call. Successful calls are logged at debug level,
+// failed ones at warning level.
+func (client *Client) logCall(ctx context.Context, inv *Invocation, info CallInfo) {
+	level := slog.LevelDebug
+	if info.Err != nil {
+		level = slog.LevelWarn
+	}
+	if !client.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("command", info.Command),
+		slog.Any("params", sanitizeParams(inv.Params)),
+		slog.Duration("latency", info.Latency),
+		slog.Int("status", info.StatusCode),
+	}
+	if info.Err != nil {
+		attrs = append(attrs, slog.String("error", info.Err.Error()))
+		if len(info.ApiErrors) > 0 {
+			attrs = append(attrs, slog.Any("api_errors", info.ApiErrors))
+		}
+	}
+	client.Logger.LogAttrs(ctx, level, "namecheap api call", attrs...)
+}
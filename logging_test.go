@@ -0,0 +1,92 @@
+package namecheap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeParams(t *testing.T) {
+	p := url.Values{}
+	p.Set("ApiKey", "anToken")
+	p.Set("CSR", "-----BEGIN CERTIFICATE REQUEST-----")
+	p.Set("NewPassword", "hunter2")
+	p.Set("DomainName", "example.com")
+
+	sanitized := sanitizeParams(p)
+	want := url.Values{
+		"ApiKey":      {redacted},
+		"CSR":         {redacted},
+		"NewPassword": {redacted},
+		"DomainName":  {"example.com"},
+	}
+	if !reflect.DeepEqual(sanitized, want) {
+		t.Errorf("sanitizeParams returned %v, want %v", sanitized, want)
+	}
+	if p.Get("ApiKey") != "anToken" {
+		t.Errorf("sanitizeParams modified its argument")
+	}
+}
+
+func TestLogger(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var buf bytes.Buffer
+	client.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	fail := false
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2019166">Domain not found</Error></Errors>
+</ApiResponse>`)
+			return
+		}
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	if _, err := client.SslActivate(SslActivateParams{CertificateId: 1, Csr: "-----BEGIN CERTIFICATE REQUEST-----"}); err != nil {
+		t.Fatalf("SslActivate returned error: %v", err)
+	}
+	fail = true
+	if _, err := client.DomainGetInfo("example.com"); err == nil {
+		t.Fatal("Expected error for error response")
+	}
+
+	if out := buf.String(); strings.Contains(out, "anToken") || strings.Contains(out, "BEGIN CERTIFICATE") {
+		t.Errorf("Log output contains secrets:\n%s", out)
+	}
+
+	type logRecord struct {
+		Level     string
+		Command   string              `json:"command"`
+		Params    map[string][]string `json:"params"`
+		Status    int                 `json:"status"`
+		ApiErrors []int               `json:"api_errors"`
+	}
+	var records []logRecord
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record logRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 log records, got %d", len(records))
+	}
+	if r := records[0]; r.Level != "DEBUG" || r.Command != sslActivate || r.Status != http.StatusOK || r.Params["CertificateID"][0] != "1" {
+		t.Errorf("Unexpected record for successful call: %+v", r)
+	}
+	if r := records[1]; r.Level != "WARN" || r.Command != domainsGetInfo || !reflect.DeepEqual(r.ApiErrors, []int{2019166}) {
+		t.Errorf("Unexpected record for failed call: %+v", r)
+	}
+}
@@ -0,0 +1,100 @@
+package namecheap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseMetadata is the information the API returns with every response,
+// besides the result of the command.
+type ResponseMetadata struct {
+	Warnings          []ApiError `xml:"Warnings>Warning"`
+	Server            string     `xml:"Server"`
+	GMTTimeDifference string     `xml:"GMTTimeDifference"`
+	ExecutionTime     string     `xml:"ExecutionTime"`
+}
+
+// GMTOffset parses GMTTimeDifference, the offset from GMT of the dates in
+// the response, such as "--5:00" or "+5:30".
+func (md *ResponseMetadata) GMTOffset() (time.Duration, error) {
+	s := strings.TrimSpace(md.GMTTimeDifference)
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "--"):
+		sign, s = -1, s[2:]
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	hours, minutes, ok := strings.Cut(s, ":")
+	if !ok {
+		minutes = "0"
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return 0, fmt.Errorf("invalid GMT time difference %q", md.GMTTimeDifference)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m >= 60 {
+		return 0, fmt.Errorf("invalid GMT time difference %q", md.GMTTimeDifference)
+	}
+	return sign * (time.Duration(h)*time.Hour + time.Duration(m)*time.Minute), nil
+}
+
+// Location returns the time zone of the dates in the response.
+func (md *ResponseMetadata) Location() (*time.Location, error) {
+	offset, err := md.GMTOffset()
+	if err != nil {
+		return nil, err
+	}
+	return time.FixedZone("GMT"+strings.TrimPrefix(md.GMTTimeDifference, "-"), int(offset/time.Second)), nil
+}
+
+// ExecutionDuration parses ExecutionTime, the time the API spent on the
+// call, in seconds.
+func (md *ResponseMetadata) ExecutionDuration() (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(md.ExecutionTime), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid execution time %q", md.ExecutionTime)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+type metadataKey struct{}
+
+// metadataCapture is the destination of the metadata captured by a context.
+type metadataCapture struct {
+	mu sync.Mutex
+	md *ResponseMetadata
+}
+
+// CaptureResponseMetadata returns a context that makes the calls it is
+// passed to store the metadata of their response in md. The metadata is
+// stored for failed calls too, when the API returned a response.
+//
+//	var md namecheap.ResponseMetadata
+//	domains, err := client.DomainsGetListContext(namecheap.CaptureResponseMetadata(ctx, &md))
+//
+// The context captures the metadata of a single call: every call it is
+// passed to overwrites md, so calls made with it concurrently or one after
+// the other leave md with the metadata of whichever answered last. Calls
+// whose metadata is needed should each be given a context of their own,
+// and md should only be read once they returned.
+func CaptureResponseMetadata(ctx context.Context, md *ResponseMetadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, &metadataCapture{md: md})
+}
+
+// storeMetadata stores md in the metadata captured by ctx, if any.
+func storeMetadata(ctx context.Context, md ResponseMetadata) {
+	if c, ok := ctx.Value(metadataKey{}).(*metadataCapture); ok && c.md != nil {
+		c.mu.Lock()
+		*c.md = md
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,125 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCaptureResponseMetadata(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fail := false
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		status, errs := "OK", ""
+		if fail {
+			status, errs = "ERROR", `<Error Number="2019166">Domain not found</Error>`
+		}
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="%s" xmlns="http://api.namecheap.com/xml.response">
+  <Errors>%s</Errors>
+  <Warnings><Warning Number="123">Deprecated command</Warning></Warnings>
+  <RequestedCommand>namecheap.domains.getList</RequestedCommand>
+  <CommandResponse Type="namecheap.domains.getList"><DomainGetListResult /></CommandResponse>
+  <Server>WEB1-SANDBOX1</Server>
+  <GMTTimeDifference>--5:00</GMTTimeDifference>
+  <ExecutionTime>0.25</ExecutionTime>
+</ApiResponse>`, status, errs)
+	})
+
+	var md ResponseMetadata
+	if _, err := client.DomainsGetListContext(CaptureResponseMetadata(context.Background(), &md)); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	want := ResponseMetadata{
+		Warnings:          []ApiError{{Number: 123, Message: "Deprecated command"}},
+		Server:            "WEB1-SANDBOX1",
+		GMTTimeDifference: "--5:00",
+		ExecutionTime:     "0.25",
+	}
+	if !reflect.DeepEqual(md, want) {
+		t.Errorf("Captured metadata %+v, want %+v", md, want)
+	}
+	if d, err := md.ExecutionDuration(); err != nil || d != 250*time.Millisecond {
+		t.Errorf("ExecutionDuration returned %v, %v, want 250ms", d, err)
+	}
+
+	fail = true
+	md = ResponseMetadata{}
+	_, err := client.DomainGetInfoContext(CaptureResponseMetadata(context.Background(), &md), "example.com")
+	if !errors.Is(err, ErrDomainNotFound) {
+		t.Fatalf("DomainGetInfo returned %v, want ErrDomainNotFound", err)
+	}
+	if md.Server != "WEB1-SANDBOX1" {
+		t.Errorf("Metadata of failed call not captured: %+v", md)
+	}
+}
+
+func TestGMTOffset(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{"--5:00", -5 * time.Hour, false},
+		{"-5:00", -5 * time.Hour, false},
+		{"+5:30", 5*time.Hour + 30*time.Minute, false},
+		{"0:00", 0, false},
+		{"3", 3 * time.Hour, false},
+		{"", 0, true},
+		{"5:75", 0, true},
+	}
+	for _, test := range tests {
+		md := ResponseMetadata{GMTTimeDifference: test.in}
+		got, err := md.GMTOffset()
+		if (err != nil) != test.err || got != test.want {
+			t.Errorf("GMTOffset of %q returned %v, %v, want %v", test.in, got, err, test.want)
+		}
+	}
+
+	md := ResponseMetadata{GMTTimeDifference: "--5:00"}
+	loc, err := md.Location()
+	if err != nil {
+		t.Fatalf("Location returned error: %v", err)
+	}
+	date := time.Date(2020, 1, 2, 12, 0, 0, 0, loc)
+	if want := time.Date(2020, 1, 2, 17, 0, 0, 0, time.UTC); !date.Equal(want) {
+		t.Errorf("Date in %v is %v, want %v", loc, date.UTC(), want)
+	}
+}
+
+func TestCaptureResponseMetadataConcurrentCalls(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <CommandResponse Type="namecheap.domains.getList"><DomainGetListResult /></CommandResponse>
+  <Server>WEB1-SANDBOX1</Server>
+</ApiResponse>`)
+	})
+
+	var md ResponseMetadata
+	ctx := CaptureResponseMetadata(context.Background(), &md)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.DomainsGetListContext(ctx); err != nil {
+				t.Errorf("DomainsGetList returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if md.Server != "WEB1-SANDBOX1" {
+		t.Errorf("Captured metadata %+v, want the one of the last call", md)
+	}
+}
@@ -4,17 +4,22 @@
 package namecheap
 
 import (
-	"encoding/xml"
+	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
-const defaultBaseURL = "https://api.namecheap.com/xml.response"
+const (
+	defaultBaseURL = "https://api.namecheap.com/xml.response"
+	sandboxBaseURL = "https://api.sandbox.namecheap.com/xml.response"
+)
 
 // Client represents a client used to make calls to the Namecheap API.
 type Client struct {
@@ -24,12 +29,87 @@ type Client struct {
 	HttpClient *http.Client
 	ClientIp   string
 
+	// Credentials, if set, supplies the API key of every request instead
+	// of ApiToken.
+	Credentials CredentialsProvider
+
+	// IPDetector, if set, supplies the ClientIp of every call instead of
+	// ClientIp. It is consulted for every call, so detectors that make
+	// requests should be wrapped by NewCachedIPDetector.
+	IPDetector IPDetector
+
 	// Base URL for API requests.
 	// Defaults to the public Namecheap API,
 	// but can be set to a different endpoint (e.g. the sandbox).
 	// BaseURL should always be specified with a trailing slash.
 	BaseURL string
 
+	// UserAgent, if set, is sent as the User-Agent header of every request.
+	UserAgent string
+
+	// Timeout, if positive, bounds every call, including its retries and
+	// rate limit waits, unless the call's context has an earlier deadline.
+	Timeout time.Duration
+
+	// RetryPolicy controls whether failed calls are retried.
+	// A nil RetryPolicy means every call is attempted exactly once.
+	RetryPolicy *RetryPolicy
+
+	// RateLimiter, if set, paces every call, including retries,
+	// to stay within the API quotas.
+	RateLimiter *RateLimiter
+
+	// CircuitBreaker, if set, fails calls fast with ErrCircuitOpen while
+	// the API keeps failing, instead of sending every attempt.
+	CircuitBreaker *CircuitBreaker
+
+	// Interceptors wrap every call, the first one being the outermost.
+	Interceptors []Interceptor
+
+	// Logger, if set, receives a record of every call, with secret
+	// parameters such as the ApiKey redacted.
+	Logger *slog.Logger
+
+	// Tracer, if set, creates a span for every call.
+	Tracer Tracer
+
+	// Observer, if set, is notified of calls, retries and rate limit waits.
+	Observer Observer
+
+	// DryRun, if set, puts the client in dry-run mode: the requests of
+	// commands that change the account are built and passed to it instead
+	// of being sent, and their calls fail with ErrDryRun. Read-only
+	// commands are sent as usual.
+	DryRun DryRunRecorder
+
+	// Audit, if set, receives a record of every call of a command that
+	// changes the account, except for those made in dry-run mode.
+	Audit AuditSink
+
+	// OnAuditError, if set, is called with the records that Audit failed
+	// to record, since the calls they describe cannot fail anymore. If it
+	// is not set, such failures are logged to Logger, or to the default
+	// slog logger if the client has none.
+	OnAuditError func(ctx context.Context, rec AuditRecord, err error)
+
+	// Cache, if set, answers calls of read-only commands from the responses
+	// of earlier ones. It is consulted after the interceptors, so calls
+	// answered from it are still intercepted, logged and observed, with a
+	// StatusCode of zero.
+	Cache *Cache
+
+	// proxy and localAddr are the egress set by WithProxy and
+	// WithLocalAddr. ipFollowsEgress tells whether the ClientIp follows
+	// them, and egressErr why they could not be set.
+	proxy           *url.URL
+	localAddr       net.IP
+	ipFollowsEgress bool
+	egressErr       error
+
+	// Registrant is the default set of contacts of DomainCreate and
+	// DomainSetContacts, used by the calls that are not given their own.
+	// Since it is shared by every call, clients used concurrently for
+	// different owners should pass the contacts with each call instead.
 	*Registrant
 }
 
@@ -37,31 +117,38 @@ type ApiRequest struct {
 	method  string
 	command string
 	params  url.Values
+
+	// result receives the CommandResponse element of the response. Its
+	// type is specific to the command.
+	result interface{}
+
+	// kind, if set, is the kind of the command declared by the caller of
+	// Call, which takes precedence over kindOf.
+	kind CommandKind
+
+	// noCache makes the call skip the Cache of the client.
+	noCache bool
+}
+
+// commandKind returns the kind of the command of request.
+func (request *ApiRequest) commandKind() CommandKind {
+	if request.kind != 0 {
+		return request.kind
+	}
+	return kindOf(request.command)
 }
 
+// ApiResponse is the envelope common to every API response.
 type ApiResponse struct {
-	Status             string                    `xml:"Status,attr"`
-	Command            string                    `xml:"RequestedCommand"`
-	TLDList            []TLDListResult           `xml:"CommandResponse>Tlds>Tld"`
-	Domains            []DomainGetListResult     `xml:"CommandResponse>DomainGetListResult>Domain"`
-	DomainInfo         *DomainInfo               `xml:"CommandResponse>DomainGetInfoResult"`
-	DomainDNSHosts     *DomainDNSGetHostsResult  `xml:"CommandResponse>DomainDNSGetHostsResult"`
-	DomainDNSSetHosts  *DomainDNSSetHostsResult  `xml:"CommandResponse>DomainDNSSetHostsResult"`
-	DomainCreate       *DomainCreateResult       `xml:"CommandResponse>DomainCreateResult"`
-	DomainRenew        *DomainRenewResult        `xml:"CommandResponse>DomainRenewResult"`
-	DomainsCheck       []DomainCheckResult       `xml:"CommandResponse>DomainCheckResult"`
-	DomainNSInfo       *DomainNSInfoResult       `xml:"CommandResponse>DomainNSInfoResult"`
-	DomainDNSSetCustom *DomainDNSSetCustomResult `xml:"CommandResponse>DomainDNSSetCustomResult"`
-	DomainSetContacts  *DomainSetContactsResult  `xml:"CommandResponse>DomainSetContactResult"`
-	SslActivate        *SslActivateResult        `xml:"CommandResponse>SSLActivateResult"`
-	SslCreate          *SslCreateResult          `xml:"CommandResponse>SSLCreateResult"`
-	SslCertificates    []SslGetListResult        `xml:"CommandResponse>SSLListResult>SSL"`
-	UsersGetPricing    []UsersGetPricingResult   `xml:"CommandResponse>UserGetPricingResult>ProductType"`
-	WhoisguardList     []WhoisguardGetListResult `xml:"CommandResponse>WhoisguardGetListResult>Whoisguard"`
-	WhoisguardEnable   whoisguardEnableResult    `xml:"CommandResponse>WhoisguardEnableResult"`
-	WhoisguardDisable  whoisguardDisableResult   `xml:"CommandResponse>WhoisguardDisableResult"`
-	WhoisguardRenew    *WhoisguardRenewResult    `xml:"CommandResponse>WhoisguardRenewResult"`
-	Errors             ApiErrors                 `xml:"Errors>Error"`
+	Status  string
+	Command string
+	Errors  ApiErrors
+	ResponseMetadata
+
+	// Result is what the CommandResponse element was decoded into, such as
+	// the Invocation.Result of the call. It is nil if the call had nothing
+	// to decode it into.
+	Result interface{}
 }
 
 // ApiError is the format of the error returned in the api responses.
@@ -85,8 +172,10 @@ func (errs ApiErrors) Error() string {
 	return errMsg
 }
 
-func NewClient(apiUser, apiToken, userName string) *Client {
-	return &Client{
+// NewClient returns a client for the given API credentials, configured by
+// the options that follow them.
+func NewClient(apiUser, apiToken, userName string, opts ...Option) *Client {
+	client := &Client{
 		ApiUser:    apiUser,
 		ApiToken:   apiToken,
 		UserName:   userName,
@@ -94,16 +183,23 @@ func NewClient(apiUser, apiToken, userName string) *Client {
 		BaseURL:    defaultBaseURL,
 		ClientIp:   "127.0.0.1",
 	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	client.applyEgress()
+	return client
 }
 
-// NewRegistrant associates a new registrant with the
+// NewRegistrant sets the default registrant of the client. It is not safe to
+// call while the client is in use; see the package level NewRegistrant to
+// pass contacts with each call instead.
 func (client *Client) NewRegistrant(
 	firstName, lastName,
 	addr1, addr2,
 	city, state, postalCode, country,
 	phone, email string,
 ) {
-	client.Registrant = newRegistrant(
+	client.Registrant = NewRegistrant(
 		firstName, lastName,
 		addr1, addr2,
 		city, state, postalCode, country,
@@ -111,68 +207,167 @@ func (client *Client) NewRegistrant(
 	)
 }
 
-func (client *Client) do(request *ApiRequest) (*ApiResponse, error) {
+func (client *Client) do(ctx context.Context, request *ApiRequest) (*ApiResponse, error) {
 	if request.method == "" {
 		return nil, errors.New("request method cannot be blank")
 	}
+	if client.egressErr != nil {
+		return nil, client.egressErr
+	}
+
+	if client.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.Timeout)
+		defer cancel()
+	}
 
-	body, status, err := client.sendRequest(request)
+	clientIP, err := client.clientIP(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if status != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code from api: %d", status)
+
+	p := request.params
+	p.Set("ApiUser", client.ApiUser)
+	p.Set("ApiKey", redacted)
+	p.Set("UserName", client.userName(ctx))
+	p.Set("ClientIp", clientIP)
+	p.Set("Command", request.command)
+
+	target := request.result
+	inv := &Invocation{Command: request.command, Params: p, Result: target, kind: request.commandKind(), noCache: request.noCache}
+	start := time.Now()
+	var span Span
+	if client.Tracer != nil {
+		ctx, span = client.Tracer.Start(ctx, request.command)
+	}
+	var final Invoker = func(ctx context.Context, inv *Invocation) (*ApiResponse, error) {
+		request.command, request.params, request.result = inv.Command, inv.Params, inv.Result
+		return client.send(ctx, request, inv)
+	}
+	if client.Cache != nil {
+		send := final
+		final = func(ctx context.Context, inv *Invocation) (*ApiResponse, error) {
+			return client.Cache.intercept(ctx, inv, send)
+		}
+	}
+	resp, err := client.intercept(ctx, inv, final)
+	if resp == nil && err == nil {
+		err = errors.New("interceptor returned neither a response nor an error")
+	}
+	if err == nil {
+		err = copyResult(target, resp.Result)
 	}
 
-	resp := new(ApiResponse)
-	if err = xml.Unmarshal(body, resp); err != nil {
+	info := newCallInfo(inv, resp, err, time.Since(start))
+	if span != nil {
+		span.End(info)
+	}
+	if client.Logger != nil {
+		client.logCall(ctx, inv, info)
+	}
+	if client.Observer != nil {
+		client.Observer.ObserveCall(info)
+	}
+	if client.Audit != nil && inv.kind != ReadOnlyCommand && !errors.Is(err, ErrDryRun) {
+		client.audit(ctx, inv, resp, info, start)
+	}
+	if err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
+
+// send performs request and decodes its response into request.result,
+// recording the outcome of the HTTP exchange in inv.
+func (client *Client) send(ctx context.Context, request *ApiRequest, inv *Invocation) (*ApiResponse, error) {
+	if client.DryRun != nil && inv.kind != ReadOnlyCommand {
+		return nil, client.dryRun(ctx, request)
+	}
 
-	if resp.Status == "" {
-		return nil, errors.New("failed to parse xml from api")
+	httpResp, err := client.sendRequestWithRetry(ctx, request)
+	if httpResp != nil {
+		inv.StatusCode = httpResp.StatusCode
 	}
-	if resp.Status == "ERROR" {
-		return nil, resp.Errors
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from api: %d", httpResp.StatusCode)
 	}
 
+	resp, err := DecodeResponse(httpResp.Body, request.result)
+	if resp != nil {
+		storeMetadata(ctx, resp.ResponseMetadata)
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
 	return resp, nil
 }
 
-func (client *Client) makeRequest(request *ApiRequest) (*http.Request, error) {
-	p := request.params
-	p.Set("ApiUser", client.ApiUser)
-	p.Set("ApiKey", client.ApiToken)
-	p.Set("UserName", client.UserName)
-	p.Set("ClientIp", client.ClientIp)
+// makeRequest builds the HTTP request for request. The authentication
+// parameters already present in request.params are kept, except for the
+// ApiKey, which is always filled in here, from the client's Credentials if
+// it has any, so that it never needs to be part of the params seen by the
+// rest of the client.
+func (client *Client) makeRequest(ctx context.Context, request *ApiRequest) (*http.Request, error) {
+	p := make(url.Values, len(request.params)+5)
+	for k, v := range request.params {
+		p[k] = v
+	}
+	setDefault(p, "ApiUser", client.ApiUser)
+	setDefault(p, "UserName", client.UserName)
+	setDefault(p, "ClientIp", client.ClientIp)
+	apiKey := client.ApiToken
+	if client.Credentials != nil {
+		var err error
+		if apiKey, err = client.Credentials.ApiKey(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get API key: %w", err)
+		}
+	}
+	p.Set("ApiKey", apiKey)
 	p.Set("Command", request.command)
 
 	b := p.Encode()
-	req, err := http.NewRequest(request.method, client.BaseURL, strings.NewReader(b))
+	req, err := http.NewRequestWithContext(ctx, request.method, client.BaseURL, strings.NewReader(b))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Content-Length", strconv.Itoa(len(b)))
+	if client.UserAgent != "" {
+		req.Header.Set("User-Agent", client.UserAgent)
+	}
 	return req, nil
 }
 
-func (client *Client) sendRequest(request *ApiRequest) ([]byte, int, error) {
-	req, err := client.makeRequest(request)
-	if err != nil {
-		return nil, 0, err
+// setDefault sets key to value unless p already has a value for it.
+func setDefault(p url.Values, key, value string) {
+	if _, ok := p[key]; !ok {
+		p.Set(key, value)
 	}
+}
 
-	resp, err := client.HttpClient.Do(req)
+// sendRequest performs the HTTP round trip for request. When the call fails
+// because ctx was canceled or its deadline passed, the context's error is
+// returned as is, so callers can test for it with errors.Is. The caller must
+// close the body of the response.
+func (client *Client) sendRequest(ctx context.Context, request *ApiRequest) (*http.Response, error) {
+	req, err := client.makeRequest(ctx, request)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	buf, err := ioutil.ReadAll(resp.Body)
+	resp, err := client.HttpClient.Do(req)
 	if err != nil {
-		return nil, 0, err
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
 	}
-
-	return buf, resp.StatusCode, nil
+	return resp, nil
 }
@@ -1,12 +1,15 @@
 package namecheap
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 )
 
 var (
@@ -71,7 +74,7 @@ func TestMakeRequest(t *testing.T) {
 		command: "namecheap.domains.getList",
 		params:  url.Values{},
 	}
-	req, _ := c.makeRequest(requestInfo)
+	req, _ := c.makeRequest(context.Background(), requestInfo)
 
 	// correctly assembled URL
 	outURL := "https://fake-api-server/"
@@ -113,25 +116,25 @@ func TestDo(t *testing.T) {
 		command: "namecheap.domains.getList",
 		params:  url.Values{},
 	}
-	_, err := client.do(requestInfo)
+	_, err := client.do(context.Background(), requestInfo)
 	if err == nil {
 		t.Errorf("Expected error for non-200 response, got %v", err)
 	}
 
 	state = "invalid"
-	_, err = client.do(requestInfo)
+	_, err = client.do(context.Background(), requestInfo)
 	if err == nil {
 		t.Errorf("Expected error for invalid response, got %v", err)
 	}
 
 	state = "error"
-	_, err = client.do(requestInfo)
+	_, err = client.do(context.Background(), requestInfo)
 	if err == nil || err.Error() != "Error 1: Some Error\n" {
 		t.Errorf("Expected error for error response, got %v", err)
 	}
 
 	state = "ok"
-	resp, err := client.do(requestInfo)
+	resp, err := client.do(context.Background(), requestInfo)
 	if err != nil {
 		t.Errorf("Expected nil error, got %v", err)
 	}
@@ -139,3 +142,29 @@ func TestDo(t *testing.T) {
 		t.Errorf("Expected non-nil response, got %v", resp)
 	}
 }
+
+// Verify that canceled and expired contexts surface as context errors
+func TestDoContext(t *testing.T) {
+	setup()
+	defer teardown()
+
+	done := make(chan struct{})
+	defer close(done)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.DomainsGetListContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled for canceled context, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.DomainsDNSGetHostsContext(ctx, "domain", "com")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded for expired context, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package namecheapotel traces the calls of a namecheap.Client with
+// OpenTelemetry.
+//
+//	client := namecheap.NewClient(apiUser, apiToken, userName,
+//		namecheap.WithTracer(namecheapotel.NewTracer(otel.GetTracerProvider())),
+//	)
+package namecheapotel
+
+import (
+	"context"
+
+	namecheap "github.com/billputer/go-namecheap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer of this package.
+const instrumentationName = "github.com/billputer/go-namecheap"
+
+// Attribute keys set on the spans.
+const (
+	CommandKey    = attribute.Key("namecheap.command")
+	UserNameKey   = attribute.Key("namecheap.user_name")
+	DomainKey     = attribute.Key("namecheap.domain")
+	SLDKey        = attribute.Key("namecheap.sld")
+	TLDKey        = attribute.Key("namecheap.tld")
+	StatusKey     = attribute.Key("namecheap.status")
+	ErrorsKey     = attribute.Key("namecheap.error_numbers")
+	StatusCodeKey = attribute.Key("http.response.status_code")
+)
+
+// NewTracer returns a namecheap.Tracer that creates one client span per
+// API command with a tracer from provider.
+func NewTracer(provider trace.TracerProvider) namecheap.Tracer {
+	return tracer{provider.Tracer(instrumentationName)}
+}
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, command string) (context.Context, namecheap.Span) {
+	ctx, span := t.tracer.Start(ctx, command,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(CommandKey.String(command)),
+	)
+	return ctx, otelSpan{span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) End(info namecheap.CallInfo) {
+	var attrs []attribute.KeyValue
+	if info.UserName != "" {
+		attrs = append(attrs, UserNameKey.String(info.UserName))
+	}
+	if info.Domain != "" {
+		attrs = append(attrs, DomainKey.String(info.Domain))
+	}
+	if info.SLD != "" {
+		attrs = append(attrs, SLDKey.String(info.SLD), TLDKey.String(info.TLD))
+	}
+	if info.Status != "" {
+		attrs = append(attrs, StatusKey.String(info.Status))
+	}
+	if info.StatusCode != 0 {
+		attrs = append(attrs, StatusCodeKey.Int(info.StatusCode))
+	}
+	if len(info.ApiErrors) > 0 {
+		attrs = append(attrs, ErrorsKey.IntSlice(info.ApiErrors))
+	}
+	s.span.SetAttributes(attrs...)
+
+	if info.Err != nil {
+		s.span.RecordError(info.Err)
+		s.span.SetStatus(codes.Error, info.Err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,95 @@
+package namecheapotel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	namecheap "github.com/billputer/go-namecheap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("Command") == "namecheap.domains.getInfo" {
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2019166">Domain not found</Error></Errors>
+</ApiResponse>`)
+			return
+		}
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	client := namecheap.NewClient("anApiUser", "anToken", "anUser",
+		namecheap.WithBaseURL(server.URL+"/"),
+		namecheap.WithTracer(NewTracer(provider)),
+	)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	if _, err := client.DomainsDNSGetHostsContext(ctx, "domain", "com"); err != nil {
+		t.Fatalf("DomainsDNSGetHosts returned error: %v", err)
+	}
+	if _, err := client.DomainGetInfoContext(ctx, "example.com"); err == nil {
+		t.Fatal("Expected error for error response")
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+
+	getHosts, getInfo := spans[0], spans[1]
+	for _, span := range []tracetest.SpanStub{getHosts, getInfo} {
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("Span %v is not a child of the caller's span", span.Name)
+		}
+		if span.SpanKind != trace.SpanKindClient {
+			t.Errorf("Span %v has kind %v, want client", span.Name, span.SpanKind)
+		}
+	}
+
+	if getHosts.Name != "namecheap.domains.dns.getHosts" {
+		t.Errorf("Span name = %v, want the command", getHosts.Name)
+	}
+	testAttributes(t, getHosts.Attributes, map[attribute.Key]attribute.Value{
+		CommandKey:    attribute.StringValue("namecheap.domains.dns.getHosts"),
+		UserNameKey:   attribute.StringValue("anUser"),
+		DomainKey:     attribute.StringValue("domain.com"),
+		SLDKey:        attribute.StringValue("domain"),
+		TLDKey:        attribute.StringValue("com"),
+		StatusKey:     attribute.StringValue("OK"),
+		StatusCodeKey: attribute.IntValue(http.StatusOK),
+	})
+
+	testAttributes(t, getInfo.Attributes, map[attribute.Key]attribute.Value{
+		DomainKey: attribute.StringValue("example.com"),
+		StatusKey: attribute.StringValue("ERROR"),
+		ErrorsKey: attribute.IntSliceValue([]int{2019166}),
+	})
+	if getInfo.Status.Code != codes.Error {
+		t.Errorf("Span status = %v, want error", getInfo.Status.Code)
+	}
+}
+
+func testAttributes(t *testing.T, attrs []attribute.KeyValue, want map[attribute.Key]attribute.Value) {
+	t.Helper()
+	got := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, kv := range attrs {
+		got[kv.Key] = kv.Value
+	}
+	for k, v := range want {
+		if got[k].Emit() != v.Emit() {
+			t.Errorf("Attribute %v = %v, want %v", k, got[k].Emit(), v.Emit())
+		}
+	}
+}
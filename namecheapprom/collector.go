@@ -0,0 +1,137 @@
+// Package namecheapprom exports the activity of namecheap.Client values as
+// Prometheus metrics.
+//
+//	collector := namecheapprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//	client := namecheap.NewClient(apiUser, apiToken, userName,
+//		namecheap.WithObserver(collector),
+//	)
+package namecheapprom
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	namecheap "github.com/billputer/go-namecheap"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes used as the value of the "outcome" label.
+const (
+	OutcomeSuccess     = "success"
+	OutcomeApiError    = "api_error"
+	OutcomeCanceled    = "canceled"
+	OutcomeError       = "error"
+	OutcomeDryRun      = "dry_run"
+	OutcomeCircuitOpen = "circuit_open"
+)
+
+// CommandOther is the value of the "command" label of the commands that the
+// namecheap package does not wrap, such as those sent with Client.Call, so
+// that arbitrary command names cannot grow the number of series.
+const CommandOther = "other"
+
+// Collector is a prometheus.Collector fed by one or more clients through
+// the namecheap.Observer interface.
+type Collector struct {
+	requests      *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	apiErrors     *prometheus.CounterVec
+	retries       *prometheus.CounterVec
+	rateLimitWait *prometheus.HistogramVec
+}
+
+var _ namecheap.Observer = (*Collector)(nil)
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector returns a Collector with metrics named namecheap_*.
+func NewCollector() *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "namecheap_requests_total",
+			Help: "Number of Namecheap API calls by command and outcome.",
+		}, []string{"command", "outcome"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "namecheap_request_duration_seconds",
+			Help:    "Duration of Namecheap API calls, including retries, by command.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"command"}),
+		apiErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "namecheap_api_errors_total",
+			Help: "Number of errors returned by the Namecheap API by command and error number.",
+		}, []string{"command", "number"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "namecheap_retries_total",
+			Help: "Number of retried Namecheap API calls by command.",
+		}, []string{"command"}),
+		rateLimitWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "namecheap_rate_limit_wait_seconds",
+			Help:    "Time spent waiting for the client rate limiter by command.",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 5, 15, 30, 60, 300},
+		}, []string{"command"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.latency.Describe(ch)
+	c.apiErrors.Describe(ch)
+	c.retries.Describe(ch)
+	c.rateLimitWait.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.latency.Collect(ch)
+	c.apiErrors.Collect(ch)
+	c.retries.Collect(ch)
+	c.rateLimitWait.Collect(ch)
+}
+
+// ObserveCall implements namecheap.Observer.
+func (c *Collector) ObserveCall(info namecheap.CallInfo) {
+	command := commandLabel(info.Command)
+	c.requests.WithLabelValues(command, outcome(info)).Inc()
+	c.latency.WithLabelValues(command).Observe(info.Latency.Seconds())
+	for _, number := range info.ApiErrors {
+		c.apiErrors.WithLabelValues(command, strconv.Itoa(number)).Inc()
+	}
+}
+
+// ObserveRetry implements namecheap.Observer.
+func (c *Collector) ObserveRetry(command string) {
+	c.retries.WithLabelValues(commandLabel(command)).Inc()
+}
+
+// ObserveRateLimitWait implements namecheap.Observer.
+func (c *Collector) ObserveRateLimitWait(command string, wait time.Duration) {
+	c.rateLimitWait.WithLabelValues(commandLabel(command)).Observe(wait.Seconds())
+}
+
+func commandLabel(command string) string {
+	if namecheap.KnownCommand(command) {
+		return command
+	}
+	return CommandOther
+}
+
+func outcome(info namecheap.CallInfo) string {
+	switch {
+	case info.Err == nil:
+		return OutcomeSuccess
+	case len(info.ApiErrors) > 0:
+		return OutcomeApiError
+	case errors.Is(info.Err, context.Canceled), errors.Is(info.Err, context.DeadlineExceeded):
+		return OutcomeCanceled
+	case errors.Is(info.Err, namecheap.ErrDryRun):
+		return OutcomeDryRun
+	case errors.Is(info.Err, namecheap.ErrCircuitOpen):
+		return OutcomeCircuitOpen
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,100 @@
+package namecheapprom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	namecheap "github.com/billputer/go-namecheap"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch {
+		case attempts == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case r.FormValue("Command") == "namecheap.domains.getInfo":
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2019166">Domain not found</Error></Errors>
+</ApiResponse>`)
+		default:
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+		}
+	}))
+	defer server.Close()
+
+	collector := NewCollector()
+	client := namecheap.NewClient("anApiUser", "anToken", "anUser",
+		namecheap.WithBaseURL(server.URL+"/"),
+		namecheap.WithObserver(collector),
+		namecheap.WithRetryPolicy(namecheap.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+		namecheap.WithRateLimiter(namecheap.NewRateLimiter(namecheap.RateLimit{Window: time.Hour, Budget: 100})),
+	)
+
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if _, err := client.DomainGetInfo("example.com"); err == nil {
+		t.Fatal("Expected error for error response")
+	}
+	client.DryRun = new(namecheap.DryRunLog)
+	if _, err := client.DomainRenew("example.com", 1); !errors.Is(err, namecheap.ErrDryRun) {
+		t.Fatalf("Expected ErrDryRun, got %v", err)
+	}
+	for _, command := range []string{"namecheap.domains.getRegistrarLock", "namecheap.users.address.getList"} {
+		if _, err := client.Call(context.Background(), command, url.Values{}, nil); !errors.Is(err, namecheap.ErrDryRun) {
+			t.Fatalf("Expected ErrDryRun, got %v", err)
+		}
+	}
+	client.DryRun = nil
+	client.CircuitBreaker = namecheap.NewCircuitBreaker(1, time.Hour)
+	client.HttpClient = &http.Client{Transport: failingTransport{}}
+	if _, err := client.DomainsTLDList(); !errors.Is(err, namecheap.ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	expected := `
+# HELP namecheap_api_errors_total Number of errors returned by the Namecheap API by command and error number.
+# TYPE namecheap_api_errors_total counter
+namecheap_api_errors_total{command="namecheap.domains.getInfo",number="2019166"} 1
+# HELP namecheap_requests_total Number of Namecheap API calls by command and outcome.
+# TYPE namecheap_requests_total counter
+namecheap_requests_total{command="namecheap.domains.getInfo",outcome="api_error"} 1
+namecheap_requests_total{command="namecheap.domains.getList",outcome="success"} 1
+namecheap_requests_total{command="namecheap.domains.getTldList",outcome="circuit_open"} 1
+namecheap_requests_total{command="namecheap.domains.renew",outcome="dry_run"} 1
+namecheap_requests_total{command="other",outcome="dry_run"} 2
+# HELP namecheap_retries_total Number of retried Namecheap API calls by command.
+# TYPE namecheap_retries_total counter
+namecheap_retries_total{command="namecheap.domains.getList"} 1
+namecheap_retries_total{command="namecheap.domains.getTldList"} 1
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"namecheap_api_errors_total", "namecheap_requests_total", "namecheap_retries_total")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if n := testutil.CollectAndCount(collector, "namecheap_request_duration_seconds"); n != 5 {
+		t.Errorf("Expected latency histograms for 5 commands, got %d", n)
+	}
+	if n := testutil.CollectAndCount(collector, "namecheap_rate_limit_wait_seconds"); n != 3 {
+		t.Errorf("Expected rate limit wait histograms for 3 commands, got %d", n)
+	}
+}
+
+// failingTransport fails every request as if the connection was lost.
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection reset by peer")
+}
@@ -0,0 +1,254 @@
+package namecheaptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette without any network
+	// access.
+	ModeReplay Mode = iota
+
+	// ModeRecord forwards requests to the real API and stores the
+	// interactions in the cassette.
+	ModeRecord
+)
+
+// Scrubbed replaces the values of credentials in stored interactions.
+const Scrubbed = "SCRUBBED"
+
+// ErrNoInteraction is returned in replay mode for a request that matches no
+// interaction of the cassette.
+var ErrNoInteraction = errors.New("namecheaptest: no recorded interaction matches the request")
+
+// scrubbedParams hold credentials, or values specific to the machine that
+// made the request. They are not stored and not used for matching.
+var scrubbedParams = []string{"ApiUser", "ApiKey", "UserName", "ClientIp"}
+
+// Interaction is a request and response stored in a cassette.
+type Interaction struct {
+	Command    string     `json:"command"`
+	Params     url.Values `json:"params"`
+	StatusCode int        `json:"status_code"`
+	Body       string     `json:"body"`
+}
+
+// Recorder is an http.RoundTripper, to be used as the Transport of
+// Client.HttpClient, that records API interactions to a cassette file or
+// replays them from it.
+//
+// Requests are matched on their command and their parameters other than
+// ApiUser, ApiKey, UserName and ClientIp, whose values are never stored.
+// The values of ApiKey, ApiUser and UserName are also scrubbed from the
+// attributes and elements of recorded responses that hold nothing else.
+// When several interactions match a request they are replayed in the order
+// they were recorded, the last one being repeated.
+type Recorder struct {
+	// Transport makes the requests in record mode. It defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	path string
+	mode Mode
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder returns a Recorder for the cassette stored at path. In replay
+// mode the cassette must exist. In record mode it is written by Save.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &r.interactions); err != nil {
+		return nil, fmt.Errorf("namecheaptest: reading cassette %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// Interactions returns the interactions of the cassette.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Save writes the recorded interactions to the cassette file. It does
+// nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(b, '\n'), 0o644)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	p, err := requestParams(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeRecord {
+		return r.record(req, p)
+	}
+	return r.replay(req, p)
+}
+
+func (r *Recorder) record(req *http.Request, p url.Values) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Command:    p.Get("Command"),
+		Params:     normalizeParams(p),
+		StatusCode: resp.StatusCode,
+		Body:       scrubBody(string(body), p),
+	})
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, p url.Values) (*http.Response, error) {
+	command := p.Get("Command")
+	key := normalizeParams(p).Encode()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	match := -1
+	for i, in := range r.interactions {
+		if !strings.EqualFold(in.Command, command) || in.Params.Encode() != key {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, command, key)
+	}
+	r.used[match] = true
+
+	in := r.interactions[match]
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+		StatusCode:    in.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/xml; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(in.Body)),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}, nil
+}
+
+// requestParams returns the parameters of req, from both its URL and its
+// form-encoded body. The body is read from a copy when req has GetBody, and
+// replaced otherwise, so that it can still be sent.
+func requestParams(req *http.Request) (url.Values, error) {
+	p := req.URL.Query()
+	if req.Body == nil || req.Body == http.NoBody {
+		return p, nil
+	}
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		if body, err = io.ReadAll(rc); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range form {
+		p[k] = append(p[k], v...)
+	}
+	return p, nil
+}
+
+// normalizeParams returns the parameters of p that identify a request.
+func normalizeParams(p url.Values) url.Values {
+	normalized := make(url.Values, len(p))
+	for k, v := range p {
+		if k == "Command" || contains(scrubbedParams, k) {
+			continue
+		}
+		normalized[k] = append([]string(nil), v...)
+	}
+	return normalized
+}
+
+// scrubBody replaces the credentials of p in a response body. Only whole
+// attribute values and element texts equal to a credential are replaced, so
+// that names merely containing one, such as domains, are kept as received.
+func scrubBody(body string, p url.Values) string {
+	for _, key := range []string{"ApiKey", "ApiUser", "UserName"} {
+		v := p.Get(key)
+		if v == "" {
+			continue
+		}
+		var escaped strings.Builder
+		xml.EscapeText(&escaped, []byte(v))
+		for _, delims := range [][2]string{{`="`, `"`}, {`='`, `'`}, {`>`, `<`}} {
+			body = strings.ReplaceAll(body, delims[0]+escaped.String()+delims[1], delims[0]+Scrubbed+delims[1])
+		}
+	}
+	return body
+}
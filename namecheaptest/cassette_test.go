@@ -0,0 +1,126 @@
+package namecheaptest_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	namecheap "github.com/billputer/go-namecheap"
+	"github.com/billputer/go-namecheap/namecheaptest"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	srv := namecheaptest.NewServer()
+	srv.ApiUser, srv.ApiKey, srv.UserName = "jdoe", "s3cret", "jdoe"
+	srv.AddDomain("example.com")
+
+	rec, err := namecheaptest.NewRecorder(path, namecheaptest.ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder returned error: %v", err)
+	}
+	client := namecheap.NewClient("jdoe", "s3cret", "jdoe",
+		namecheap.WithBaseURL(srv.URL),
+		namecheap.WithHTTPClient(&http.Client{Transport: rec}),
+	)
+	recorded, err := client.DomainsGetList()
+	if err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if err := client.WhoisguardDisable(1); err == nil {
+		t.Fatal("WhoisguardDisable of an unknown ID returned no error")
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	srv.Close()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); strings.Contains(s, "s3cret") || strings.Contains(s, "jdoe") {
+		t.Errorf("Cassette contains credentials:\n%s", s)
+	}
+
+	rec, err = namecheaptest.NewRecorder(path, namecheaptest.ModeReplay)
+	if err != nil {
+		t.Fatalf("NewRecorder returned error: %v", err)
+	}
+	if n := len(rec.Interactions()); n != 2 {
+		t.Fatalf("Cassette has %d interactions, want 2", n)
+	}
+	client = namecheap.NewClient("other", "otherkey", "other",
+		namecheap.WithBaseURL(srv.URL),
+		namecheap.WithHTTPClient(&http.Client{Transport: rec}),
+		namecheap.WithRetryPolicy(namecheap.RetryPolicy{MaxAttempts: 1}),
+	)
+	for i := 0; i < 2; i++ {
+		replayed, err := client.DomainsGetList()
+		if err != nil {
+			t.Fatalf("Replayed DomainsGetList returned error: %v", err)
+		}
+		recorded[0].User = namecheaptest.Scrubbed
+		if !reflect.DeepEqual(replayed, recorded) {
+			t.Errorf("Replayed DomainsGetList returned %+v, want %+v", replayed, recorded)
+		}
+	}
+	if err := client.WhoisguardDisable(1); !namecheap.HasApiErrorNumber(err, namecheaptest.ErrNumberInvalidParameter) {
+		t.Errorf("Replayed WhoisguardDisable returned %v, want the recorded error", err)
+	}
+	if err := client.WhoisguardDisable(2); !errors.Is(err, namecheaptest.ErrNoInteraction) {
+		t.Errorf("WhoisguardDisable with other params returned %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestRecorderScrubsWholeValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	srv := namecheaptest.NewServer()
+	defer srv.Close()
+	srv.ApiUser, srv.ApiKey, srv.UserName = "john", "s3cret", "john"
+	srv.AddDomain("johnsmith.com")
+
+	rec, err := namecheaptest.NewRecorder(path, namecheaptest.ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder returned error: %v", err)
+	}
+	client := namecheap.NewClient("john", "s3cret", "john",
+		namecheap.WithBaseURL(srv.URL),
+		namecheap.WithHTTPClient(&http.Client{Transport: rec}),
+	)
+	recorded, err := client.DomainsGetList()
+	if err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	rec, err = namecheaptest.NewRecorder(path, namecheaptest.ModeReplay)
+	if err != nil {
+		t.Fatalf("NewRecorder returned error: %v", err)
+	}
+	body := rec.Interactions()[0].Body
+	if !strings.Contains(body, `"johnsmith.com"`) || strings.Contains(body, `"john"`) {
+		t.Errorf("Cassette body was not scrubbed of whole values only:\n%s", body)
+	}
+
+	client = namecheap.NewClient("other", "otherkey", "other",
+		namecheap.WithBaseURL(srv.URL),
+		namecheap.WithHTTPClient(&http.Client{Transport: rec}),
+	)
+	replayed, err := client.DomainsGetList()
+	if err != nil {
+		t.Fatalf("Replayed DomainsGetList returned error: %v", err)
+	}
+	recorded[0].User = namecheaptest.Scrubbed
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("Replayed DomainsGetList returned %+v, want %+v", replayed, recorded)
+	}
+}
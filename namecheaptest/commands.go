@@ -0,0 +1,850 @@
+package namecheaptest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handler runs a command against the state of the server, which is locked.
+type handler func(s *Server, p url.Values) ([]interface{}, *apiError)
+
+var handlers = map[string]handler{
+	"namecheap.domains.getlist":       (*Server).domainsGetList,
+	"namecheap.domains.getinfo":       (*Server).domainsGetInfo,
+	"namecheap.domains.check":         (*Server).domainsCheck,
+	"namecheap.domains.gettldlist":    (*Server).domainsGetTLDList,
+	"namecheap.domains.create":        (*Server).domainsCreate,
+	"namecheap.domains.renew":         (*Server).domainsRenew,
+	"namecheap.domains.setcontacts":   (*Server).domainsSetContacts,
+	"namecheap.domains.dns.gethosts":  (*Server).dnsGetHosts,
+	"namecheap.domains.dns.sethosts":  (*Server).dnsSetHosts,
+	"namecheap.domains.dns.setcustom": (*Server).dnsSetCustom,
+	"namecheap.domains.ns.getinfo":    (*Server).nsGetInfo,
+	"namecheap.ssl.getlist":           (*Server).sslGetList,
+	"namecheap.ssl.create":            (*Server).sslCreate,
+	"namecheap.ssl.activate":          (*Server).sslActivate,
+	"namecheap.users.getpricing":      (*Server).usersGetPricing,
+	"namecheap.users.getbalances":     (*Server).usersGetBalances,
+	"namecheap.whoisguard.getlist":    (*Server).whoisguardGetList,
+	"namecheap.whoisguard.enable":     (*Server).whoisguardEnable,
+	"namecheap.whoisguard.disable":    (*Server).whoisguardDisable,
+	"namecheap.whoisguard.renew":      (*Server).whoisguardRenew,
+}
+
+// tlds are the TLDs the fake sells.
+var tlds = []string{"com", "net", "org", "info", "io"}
+
+// contactTypes and contactFields make up the required contact parameters,
+// such as RegistrantFirstName.
+var (
+	contactTypes  = []string{"Registrant", "Tech", "Admin", "AuxBilling"}
+	contactFields = []string{
+		"FirstName", "LastName", "Address1", "City", "StateProvince",
+		"PostalCode", "Country", "Phone", "EmailAddress",
+	}
+)
+
+func (s *Server) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) addDomain(name string, years int) *Domain {
+	now := s.Now()
+	d := &Domain{
+		ID:               s.newID(),
+		Name:             name,
+		Created:          now,
+		Expires:          now.AddDate(years, 0, 0),
+		ChildNameservers: make(map[string]string),
+		Contacts:         make(url.Values),
+		Whoisguard: Whoisguard{
+			ID:      int64(s.newID()),
+			Expires: now.AddDate(years, 0, 0),
+		},
+	}
+	s.domains[name] = d
+	return d
+}
+
+// ownedDomain returns the domain called name, or an error if it is not in
+// the account.
+func (s *Server) ownedDomain(name string) (*Domain, *apiError) {
+	if name == "" {
+		return nil, errorf(ErrNumberMissingParameter, "Parameter DomainName is missing")
+	}
+	d, ok := s.domains[strings.ToLower(name)]
+	if !ok {
+		return nil, errorf(ErrNumberDomainNotOwned, "Domain is not associated with your account")
+	}
+	return d, nil
+}
+
+// domainFromSLD returns the domain named by the SLD and TLD parameters.
+func (s *Server) domainFromSLD(p url.Values) (*Domain, *apiError) {
+	if p.Get("SLD") == "" || p.Get("TLD") == "" {
+		return nil, errorf(ErrNumberMissingParameter, "Parameters SLD and TLD are required")
+	}
+	return s.ownedDomain(p.Get("SLD") + "." + p.Get("TLD"))
+}
+
+func (s *Server) whoisguardByID(p url.Values) (*Domain, *apiError) {
+	id, err := strconv.ParseInt(p.Get("WhoisguardID"), 10, 64)
+	if err != nil {
+		return nil, errorf(ErrNumberInvalidParameter, "Parameter WhoisguardID is invalid")
+	}
+	for _, d := range s.domains {
+		if d.Whoisguard.ID == id {
+			return d, nil
+		}
+	}
+	return nil, errorf(ErrNumberInvalidParameter, "Whoisguard %d not found", id)
+}
+
+// intParam returns the integer parameter key, or def if it is not set.
+func intParam(p url.Values, key string, def int) (int, *apiError) {
+	v := p.Get(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, errorf(ErrNumberInvalidParameter, "Parameter %s is invalid", key)
+	}
+	return n, nil
+}
+
+// charge takes amount from the balance of the account.
+func (s *Server) charge(amount float64) *apiError {
+	if amount > s.Balance {
+		return errorf(ErrNumberOrderFailed, "Order creation failed: insufficient funds")
+	}
+	s.Balance -= amount
+	return nil
+}
+
+// contacts returns the contact parameters of p, or an error if a required
+// one is missing.
+func contacts(p url.Values) (url.Values, *apiError) {
+	c := make(url.Values)
+	for _, contactType := range contactTypes {
+		for _, field := range contactFields {
+			key := contactType + field
+			if p.Get(key) == "" {
+				return nil, errorf(ErrNumberMissingParameter, "Parameter %s is missing", key)
+			}
+		}
+	}
+	for k, v := range p {
+		for _, contactType := range contactTypes {
+			if strings.HasPrefix(k, contactType) {
+				c[k] = append([]string(nil), v...)
+			}
+		}
+	}
+	return c, nil
+}
+
+type xmlDomain struct {
+	XMLName    xml.Name `xml:"Domain"`
+	ID         int      `xml:"ID,attr"`
+	Name       string   `xml:"Name,attr"`
+	User       string   `xml:"User,attr"`
+	Created    string   `xml:"Created,attr"`
+	Expires    string   `xml:"Expires,attr"`
+	IsExpired  bool     `xml:"IsExpired,attr"`
+	IsLocked   bool     `xml:"IsLocked,attr"`
+	AutoRenew  bool     `xml:"AutoRenew,attr"`
+	WhoisGuard string   `xml:"WhoisGuard,attr"`
+}
+
+type xmlPaging struct {
+	XMLName     xml.Name `xml:"Paging"`
+	TotalItems  int      `xml:"TotalItems"`
+	CurrentPage int      `xml:"CurrentPage"`
+	PageSize    int      `xml:"PageSize"`
+}
+
+func whoisguardStatus(d *Domain) string {
+	if d.Whoisguard.Enabled {
+		return "ENABLED"
+	}
+	return "DISABLED"
+}
+
+func (s *Server) domainsGetList(p url.Values) ([]interface{}, *apiError) {
+	page, err := intParam(p, "Page", 1)
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := intParam(p, "PageSize", 20)
+	if err != nil {
+		return nil, err
+	}
+	if page < 1 || pageSize < 1 {
+		return nil, errorf(ErrNumberInvalidParameter, "Parameters Page and PageSize must be positive")
+	}
+
+	names := make([]string, 0, len(s.domains))
+	for name := range s.domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := s.Now()
+	var list struct {
+		XMLName xml.Name `xml:"DomainGetListResult"`
+		Domains []xmlDomain
+	}
+	for i := (page - 1) * pageSize; i < len(names) && i < page*pageSize; i++ {
+		d := s.domains[names[i]]
+		list.Domains = append(list.Domains, xmlDomain{
+			ID:         d.ID,
+			Name:       d.Name,
+			User:       s.UserName,
+			Created:    d.Created.Format(dateFormat),
+			Expires:    d.Expires.Format(dateFormat),
+			IsExpired:  now.After(d.Expires),
+			IsLocked:   d.IsLocked,
+			AutoRenew:  d.AutoRenew,
+			WhoisGuard: whoisguardStatus(d),
+		})
+	}
+	paging := xmlPaging{TotalItems: len(names), CurrentPage: page, PageSize: pageSize}
+	return []interface{}{list, paging}, nil
+}
+
+func (s *Server) domainsGetInfo(p url.Values) ([]interface{}, *apiError) {
+	d, ok := s.domains[strings.ToLower(p.Get("DomainName"))]
+	if !ok {
+		return nil, errorf(ErrNumberDomainNotFound, "Domain not found")
+	}
+
+	type dnsDetails struct {
+		ProviderType  string   `xml:"ProviderType,attr"`
+		IsUsingOurDNS bool     `xml:"IsUsingOurDNS,attr"`
+		Nameservers   []string `xml:"Nameserver"`
+	}
+	type whoisguard struct {
+		Enabled     string `xml:"Enabled,attr"`
+		ID          int64  `xml:"ID"`
+		ExpiredDate string `xml:"ExpiredDate"`
+	}
+	info := struct {
+		XMLName     xml.Name   `xml:"DomainGetInfoResult"`
+		Status      string     `xml:"Status,attr"`
+		ID          int        `xml:"ID,attr"`
+		DomainName  string     `xml:"DomainName,attr"`
+		OwnerName   string     `xml:"OwnerName,attr"`
+		IsOwner     bool       `xml:"IsOwner,attr"`
+		IsExpired   bool       `xml:"IsExpired,attr"`
+		IsLocked    bool       `xml:"IsLocked,attr"`
+		AutoRenew   bool       `xml:"AutoRenew,attr"`
+		CreatedDate string     `xml:"DomainDetails>CreatedDate"`
+		ExpiredDate string     `xml:"DomainDetails>ExpiredDate"`
+		Whoisguard  whoisguard `xml:"Whoisguard"`
+		DNSDetails  dnsDetails `xml:"DnsDetails"`
+	}{
+		Status:      "Ok",
+		ID:          d.ID,
+		DomainName:  d.Name,
+		OwnerName:   s.UserName,
+		IsOwner:     true,
+		IsExpired:   s.Now().After(d.Expires),
+		IsLocked:    d.IsLocked,
+		AutoRenew:   d.AutoRenew,
+		CreatedDate: d.Created.Format(dateFormat),
+		ExpiredDate: d.Expires.Format(dateFormat),
+		Whoisguard: whoisguard{
+			Enabled:     enabledString(d.Whoisguard.Enabled),
+			ID:          d.Whoisguard.ID,
+			ExpiredDate: d.Whoisguard.Expires.Format(dateFormat),
+		},
+		DNSDetails: dnsDetails{
+			ProviderType:  "FREE",
+			IsUsingOurDNS: len(d.Nameservers) == 0,
+			Nameservers:   d.Nameservers,
+		},
+	}
+	if len(d.Nameservers) == 0 {
+		info.DNSDetails.Nameservers = []string{"dns1.registrar-servers.com", "dns2.registrar-servers.com"}
+	} else {
+		info.DNSDetails.ProviderType = "CUSTOM"
+	}
+	return []interface{}{info}, nil
+}
+
+func (s *Server) domainsCheck(p url.Values) ([]interface{}, *apiError) {
+	if p.Get("DomainList") == "" {
+		return nil, errorf(ErrNumberMissingParameter, "Parameter DomainList is missing")
+	}
+
+	type checkResult struct {
+		XMLName   xml.Name `xml:"DomainCheckResult"`
+		Domain    string   `xml:"Domain,attr"`
+		Available bool     `xml:"Available,attr"`
+	}
+	var results []interface{}
+	for _, name := range strings.Split(p.Get("DomainList"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		results = append(results, checkResult{Domain: name, Available: s.available(name)})
+	}
+	return results, nil
+}
+
+// available reports whether name can be registered.
+func (s *Server) available(name string) bool {
+	_, owned := s.domains[name]
+	return !owned && !s.taken[name]
+}
+
+func (s *Server) domainsGetTLDList(p url.Values) ([]interface{}, *apiError) {
+	type tld struct {
+		Name              string `xml:"Name,attr"`
+		IsApiRegisterable bool   `xml:"IsApiRegisterable,attr"`
+	}
+	list := struct {
+		XMLName xml.Name `xml:"Tlds"`
+		Tlds    []tld    `xml:"Tld"`
+	}{}
+	for _, name := range tlds {
+		list.Tlds = append(list.Tlds, tld{Name: name, IsApiRegisterable: true})
+	}
+	return []interface{}{list}, nil
+}
+
+func (s *Server) domainsCreate(p url.Values) ([]interface{}, *apiError) {
+	name := strings.ToLower(p.Get("DomainName"))
+	if name == "" {
+		return nil, errorf(ErrNumberMissingParameter, "Parameter DomainName is missing")
+	}
+	years, err := intParam(p, "Years", 1)
+	if err != nil {
+		return nil, err
+	}
+	c, err := contacts(p)
+	if err != nil {
+		return nil, err
+	}
+	if !s.available(name) {
+		return nil, errorf(ErrNumberDomainUnavailable, "Domain not available")
+	}
+
+	amount := s.DomainPrice * float64(years)
+	if err := s.charge(amount); err != nil {
+		return nil, err
+	}
+
+	d := s.addDomain(name, years)
+	d.Contacts = c
+	if p.Get("Nameservers") != "" {
+		d.Nameservers = strings.Split(p.Get("Nameservers"), ",")
+	}
+	d.Whoisguard.Enabled = strings.EqualFold(p.Get("WGEnabled"), "yes")
+
+	result := struct {
+		XMLName          xml.Name `xml:"DomainCreateResult"`
+		Domain           string   `xml:"Domain,attr"`
+		Registered       bool     `xml:"Registered,attr"`
+		ChargedAmount    float64  `xml:"ChargedAmount,attr"`
+		DomainID         int      `xml:"DomainID,attr"`
+		OrderID          int      `xml:"OrderID,attr"`
+		TransactionID    int      `xml:"TransactionID,attr"`
+		WhoisguardEnable bool     `xml:"WhoisguardEnable,attr"`
+	}{
+		Domain:           name,
+		Registered:       true,
+		ChargedAmount:    amount,
+		DomainID:         d.ID,
+		OrderID:          s.newID(),
+		TransactionID:    s.newID(),
+		WhoisguardEnable: d.Whoisguard.Enabled,
+	}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) domainsRenew(p url.Values) ([]interface{}, *apiError) {
+	d, err := s.ownedDomain(p.Get("DomainName"))
+	if err != nil {
+		return nil, err
+	}
+	years, err := intParam(p, "Years", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := s.DomainPrice * float64(years)
+	if err := s.charge(amount); err != nil {
+		return nil, err
+	}
+	d.Expires = d.Expires.AddDate(years, 0, 0)
+
+	result := struct {
+		XMLName       xml.Name `xml:"DomainRenewResult"`
+		DomainName    string   `xml:"DomainName,attr"`
+		DomainID      int      `xml:"DomainID,attr"`
+		Renew         bool     `xml:"Renew,attr"`
+		ChargedAmount float64  `xml:"ChargedAmount,attr"`
+		OrderID       int      `xml:"OrderID,attr"`
+		TransactionID int      `xml:"TransactionID,attr"`
+		ExpiredDate   string   `xml:"DomainDetails>ExpiredDate"`
+	}{
+		DomainName:    d.Name,
+		DomainID:      d.ID,
+		Renew:         true,
+		ChargedAmount: amount,
+		OrderID:       s.newID(),
+		TransactionID: s.newID(),
+		ExpiredDate:   d.Expires.Format(dateFormat),
+	}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) domainsSetContacts(p url.Values) ([]interface{}, *apiError) {
+	d, err := s.ownedDomain(p.Get("DomainName"))
+	if err != nil {
+		return nil, err
+	}
+	c, err := contacts(p)
+	if err != nil {
+		return nil, err
+	}
+	d.Contacts = c
+
+	result := struct {
+		XMLName   xml.Name `xml:"DomainSetContactResult"`
+		Domain    string   `xml:"Domain,attr"`
+		IsSuccess bool     `xml:"IsSuccess,attr"`
+	}{Domain: d.Name, IsSuccess: true}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) dnsGetHosts(p url.Values) ([]interface{}, *apiError) {
+	d, err := s.domainFromSLD(p)
+	if err != nil {
+		return nil, err
+	}
+
+	type host struct {
+		HostID  int    `xml:"HostId,attr"`
+		Name    string `xml:"Name,attr"`
+		Type    string `xml:"Type,attr"`
+		Address string `xml:"Address,attr"`
+		MXPref  int    `xml:"MXPref,attr"`
+		TTL     int    `xml:"TTL,attr"`
+	}
+	result := struct {
+		XMLName       xml.Name `xml:"DomainDNSGetHostsResult"`
+		Domain        string   `xml:"Domain,attr"`
+		IsUsingOurDNS bool     `xml:"IsUsingOurDNS,attr"`
+		Hosts         []host   `xml:"host"`
+	}{Domain: d.Name, IsUsingOurDNS: len(d.Nameservers) == 0}
+	for _, h := range d.Hosts {
+		result.Hosts = append(result.Hosts, host{
+			HostID:  h.ID,
+			Name:    h.Name,
+			Type:    h.Type,
+			Address: h.Address,
+			MXPref:  h.MXPref,
+			TTL:     h.TTL,
+		})
+	}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) dnsSetHosts(p url.Values) ([]interface{}, *apiError) {
+	d, err := s.domainFromSLD(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Nameservers) > 0 {
+		return nil, errorf(ErrNumberNotUsingOurDNS, "Cannot complete this command as this domain is not using proper DNS servers")
+	}
+
+	var hosts []Host
+	for i := 1; p.Get(fmt.Sprintf("HostName%d", i)) != ""; i++ {
+		h := Host{
+			ID:      s.newID(),
+			Name:    p.Get(fmt.Sprintf("HostName%d", i)),
+			Type:    p.Get(fmt.Sprintf("RecordType%d", i)),
+			Address: p.Get(fmt.Sprintf("Address%d", i)),
+		}
+		if h.Type == "" || h.Address == "" {
+			return nil, errorf(ErrNumberMissingParameter, "Parameters RecordType%d and Address%d are required", i, i)
+		}
+		if h.MXPref, err = intParam(p, fmt.Sprintf("MXPref%d", i), 10); err != nil {
+			return nil, err
+		}
+		if h.TTL, err = intParam(p, fmt.Sprintf("TTL%d", i), 1800); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, h)
+	}
+	d.Hosts = hosts
+
+	result := struct {
+		XMLName   xml.Name `xml:"DomainDNSSetHostsResult"`
+		Domain    string   `xml:"Domain,attr"`
+		IsSuccess bool     `xml:"IsSuccess,attr"`
+	}{Domain: d.Name, IsSuccess: true}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) dnsSetCustom(p url.Values) ([]interface{}, *apiError) {
+	d, err := s.domainFromSLD(p)
+	if err != nil {
+		return nil, err
+	}
+	if p.Get("Nameservers") == "" {
+		return nil, errorf(ErrNumberMissingParameter, "Parameter Nameservers is missing")
+	}
+	d.Nameservers = strings.Split(p.Get("Nameservers"), ",")
+
+	result := struct {
+		XMLName xml.Name `xml:"DomainDNSSetCustomResult"`
+		Domain  string   `xml:"Domain,attr"`
+		Update  bool     `xml:"Update,attr"`
+	}{Domain: d.Name, Update: true}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) nsGetInfo(p url.Values) ([]interface{}, *apiError) {
+	d, err := s.domainFromSLD(p)
+	if err != nil {
+		return nil, err
+	}
+	nameserver := strings.ToLower(p.Get("Nameserver"))
+	ip, ok := d.ChildNameservers[nameserver]
+	if !ok {
+		return nil, errorf(ErrNumberInvalidParameter, "Nameserver %s not found", nameserver)
+	}
+
+	result := struct {
+		XMLName    xml.Name `xml:"DomainNSInfoResult"`
+		Domain     string   `xml:"Domain,attr"`
+		Nameserver string   `xml:"Nameserver,attr"`
+		IP         string   `xml:"IP,attr"`
+		Statuses   []string `xml:"NameserverStatuses>Status"`
+	}{Domain: d.Name, Nameserver: nameserver, IP: ip, Statuses: []string{"OK"}}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) sslGetList(p url.Values) ([]interface{}, *apiError) {
+	type ssl struct {
+		CertificateID        int    `xml:"CertificateID,attr"`
+		HostName             string `xml:"HostName,attr"`
+		SSLType              string `xml:"SSLType,attr"`
+		PurchaseDate         string `xml:"PurchaseDate,attr"`
+		ExpireDate           string `xml:"ExpireDate,attr"`
+		ActivationExpireDate string `xml:"ActivationExpireDate,attr"`
+		IsExpiredYN          bool   `xml:"IsExpiredYN,attr"`
+		Status               string `xml:"Status,attr"`
+	}
+	list := struct {
+		XMLName xml.Name `xml:"SSLListResult"`
+		SSL     []ssl    `xml:"SSL"`
+	}{}
+	now := s.Now()
+	for _, cert := range s.certificates {
+		list.SSL = append(list.SSL, ssl{
+			CertificateID:        cert.ID,
+			HostName:             cert.HostName,
+			SSLType:              cert.Type,
+			PurchaseDate:         cert.Purchased.Format(dateFormat),
+			ExpireDate:           cert.Expires.Format(dateFormat),
+			ActivationExpireDate: cert.Purchased.AddDate(0, 0, 30).Format(dateFormat),
+			IsExpiredYN:          now.After(cert.Expires),
+			Status:               cert.Status,
+		})
+	}
+	return []interface{}{list}, nil
+}
+
+func (s *Server) sslCreate(p url.Values) ([]interface{}, *apiError) {
+	if p.Get("Type") == "" {
+		return nil, errorf(ErrNumberMissingParameter, "Parameter Type is missing")
+	}
+	years, err := intParam(p, "Years", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := s.SSLPrice * float64(years)
+	if err := s.charge(amount); err != nil {
+		return nil, err
+	}
+	now := s.Now()
+	cert := &SSLCertificate{
+		ID:        s.newID(),
+		Type:      p.Get("Type"),
+		Years:     years,
+		Status:    "newpurchase",
+		Purchased: now,
+		Expires:   now.AddDate(years, 0, 0),
+	}
+	s.certificates = append(s.certificates, cert)
+
+	type certificate struct {
+		CertificateID int    `xml:"CertificateID,attr"`
+		SSLType       string `xml:"SSLType,attr"`
+		Created       string `xml:"Created,attr"`
+		Years         int    `xml:"Years,attr"`
+		Status        string `xml:"Status,attr"`
+	}
+	result := struct {
+		XMLName        xml.Name    `xml:"SSLCreateResult"`
+		IsSuccess      bool        `xml:"IsSuccess,attr"`
+		OrderID        int         `xml:"OrderId,attr"`
+		TransactionID  int         `xml:"TransactionId,attr"`
+		ChargedAmount  float64     `xml:"ChargedAmount,attr"`
+		SSLCertificate certificate `xml:"SSLCertificate"`
+	}{
+		IsSuccess:     true,
+		OrderID:       s.newID(),
+		TransactionID: s.newID(),
+		ChargedAmount: amount,
+		SSLCertificate: certificate{
+			CertificateID: cert.ID,
+			SSLType:       cert.Type,
+			Created:       now.Format(dateFormat),
+			Years:         years,
+			Status:        cert.Status,
+		},
+	}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) sslActivate(p url.Values) ([]interface{}, *apiError) {
+	id, err := intParam(p, "CertificateID", 0)
+	if err != nil {
+		return nil, err
+	}
+	var cert *SSLCertificate
+	for _, c := range s.certificates {
+		if c.ID == id {
+			cert = c
+		}
+	}
+	if cert == nil {
+		return nil, errorf(ErrNumberInvalidParameter, "Certificate %d not found", id)
+	}
+	if p.Get("CSR") == "" {
+		return nil, errorf(ErrNumberMissingParameter, "Parameter CSR is missing")
+	}
+	if cert.Status != "newpurchase" {
+		return nil, errorf(ErrNumberInvalidParameter, "Certificate %d is already activated", id)
+	}
+	cert.Status = "active"
+	cert.HostName = p.Get("HostName")
+
+	type dns struct {
+		Domain   string `xml:"domain,attr"`
+		HostName string `xml:"HostName,omitempty"`
+		Target   string `xml:"Target,omitempty"`
+	}
+	type validation struct {
+		ValueAvailable bool `xml:"ValueAvailable,attr"`
+		DNS            *dns `xml:"DNS,omitempty"`
+	}
+	result := struct {
+		XMLName          xml.Name   `xml:"SSLActivateResult"`
+		ID               int        `xml:"ID,attr"`
+		IsSuccess        bool       `xml:"IsSuccess,attr"`
+		HttpDCValidation validation `xml:"HttpDCValidation"`
+		DNSDCValidation  validation `xml:"DNSDCValidation"`
+	}{ID: cert.ID, IsSuccess: true}
+	if p.Get("DNSDCValidation") == "true" {
+		result.DNSDCValidation = validation{
+			ValueAvailable: true,
+			DNS: &dns{
+				Domain:   cert.HostName,
+				HostName: fmt.Sprintf("_%d.%s", cert.ID, cert.HostName),
+				Target:   fmt.Sprintf("%d.comodoca.com", cert.ID),
+			},
+		}
+	}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) usersGetPricing(p url.Values) ([]interface{}, *apiError) {
+	if p.Get("ProductType") == "" {
+		return nil, errorf(ErrNumberMissingParameter, "Parameter ProductType is missing")
+	}
+
+	type price struct {
+		Duration     int     `xml:"Duration,attr"`
+		DurationType string  `xml:"DurationType,attr"`
+		Price        float64 `xml:"Price,attr"`
+		RegularPrice float64 `xml:"RegularPrice,attr"`
+		YourPrice    float64 `xml:"YourPrice,attr"`
+		Currency     string  `xml:"Currency,attr"`
+	}
+	type product struct {
+		Name  string  `xml:"Name,attr"`
+		Price []price `xml:"Price"`
+	}
+	type category struct {
+		Name    string    `xml:"Name,attr"`
+		Product []product `xml:"Product"`
+	}
+	type productType struct {
+		Name            string     `xml:"Name,attr"`
+		ProductCategory []category `xml:"ProductCategory"`
+	}
+	result := struct {
+		XMLName     xml.Name    `xml:"UserGetPricingResult"`
+		ProductType productType `xml:"ProductType"`
+	}{ProductType: productType{Name: p.Get("ProductType")}}
+
+	if strings.EqualFold(p.Get("ProductType"), "DOMAIN") {
+		for _, action := range []string{"REGISTER", "RENEW"} {
+			c := category{Name: action}
+			for _, tld := range tlds {
+				pr := price{Duration: 1, DurationType: "YEAR", Currency: "USD",
+					Price: s.DomainPrice, RegularPrice: s.DomainPrice, YourPrice: s.DomainPrice}
+				c.Product = append(c.Product, product{Name: tld, Price: []price{pr}})
+			}
+			result.ProductType.ProductCategory = append(result.ProductType.ProductCategory, c)
+		}
+	}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) usersGetBalances(p url.Values) ([]interface{}, *apiError) {
+	result := struct {
+		XMLName                   xml.Name `xml:"UserGetBalancesResult"`
+		Currency                  string   `xml:"Currency,attr"`
+		AvailableBalance          float64  `xml:"AvailableBalance,attr"`
+		AccountBalance            float64  `xml:"AccountBalance,attr"`
+		EarnedAmount              float64  `xml:"EarnedAmount,attr"`
+		WithdrawableAmount        float64  `xml:"WithdrawableAmount,attr"`
+		FundsRequiredForAutoRenew float64  `xml:"FundsRequiredForAutoRenew,attr"`
+	}{Currency: "USD", AvailableBalance: s.Balance, AccountBalance: s.Balance}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) whoisguardGetList(p url.Values) ([]interface{}, *apiError) {
+	type whoisguard struct {
+		ID         int64  `xml:"ID,attr"`
+		DomainName string `xml:"DomainName,attr"`
+		Created    string `xml:"Created,attr"`
+		Expires    string `xml:"Expires,attr"`
+		Status     string `xml:"Status,attr"`
+	}
+	list := struct {
+		XMLName    xml.Name     `xml:"WhoisguardGetListResult"`
+		Whoisguard []whoisguard `xml:"Whoisguard"`
+	}{}
+
+	names := make([]string, 0, len(s.domains))
+	for name := range s.domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d := s.domains[name]
+		list.Whoisguard = append(list.Whoisguard, whoisguard{
+			ID:         d.Whoisguard.ID,
+			DomainName: d.Name,
+			Created:    d.Created.Format(dateFormat),
+			Expires:    d.Whoisguard.Expires.Format(dateFormat),
+			Status:     strings.ToLower(whoisguardStatus(d)),
+		})
+	}
+	return []interface{}{list}, nil
+}
+
+type xmlWhoisguardToggleResult struct {
+	XMLName   xml.Name
+	Domain    string `xml:"Domain,attr"`
+	IsSuccess bool   `xml:"IsSuccess,attr"`
+}
+
+func (s *Server) whoisguardEnable(p url.Values) ([]interface{}, *apiError) {
+	d, err := s.whoisguardByID(p)
+	if err != nil {
+		return nil, err
+	}
+	if p.Get("ForwardedToEmail") == "" {
+		return nil, errorf(ErrNumberMissingParameter, "Parameter ForwardedToEmail is missing")
+	}
+	d.Whoisguard.Enabled = true
+	d.Whoisguard.ForwardedToEmail = p.Get("ForwardedToEmail")
+
+	result := xmlWhoisguardToggleResult{
+		XMLName:   xml.Name{Local: "WhoisguardEnableResult"},
+		Domain:    d.Name,
+		IsSuccess: true,
+	}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) whoisguardDisable(p url.Values) ([]interface{}, *apiError) {
+	d, err := s.whoisguardByID(p)
+	if err != nil {
+		return nil, err
+	}
+	d.Whoisguard.Enabled = false
+
+	result := xmlWhoisguardToggleResult{
+		XMLName:   xml.Name{Local: "WhoisguardDisableResult"},
+		Domain:    d.Name,
+		IsSuccess: true,
+	}
+	return []interface{}{result}, nil
+}
+
+func (s *Server) whoisguardRenew(p url.Values) ([]interface{}, *apiError) {
+	d, err := s.whoisguardByID(p)
+	if err != nil {
+		return nil, err
+	}
+	years, err := intParam(p, "Years", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := s.WhoisguardPrice * float64(years)
+	if err := s.charge(amount); err != nil {
+		return nil, err
+	}
+	d.Whoisguard.Expires = maxTime(d.Whoisguard.Expires, s.Now()).AddDate(years, 0, 0)
+
+	result := struct {
+		XMLName       xml.Name `xml:"WhoisguardRenewResult"`
+		WhoisguardID  int64    `xml:"WhoisguardId,attr"`
+		Years         int      `xml:"Years,attr"`
+		Renew         bool     `xml:"Renew,attr"`
+		ChargedAmount float64  `xml:"ChargedAmount,attr"`
+		OrderID       int      `xml:"OrderId,attr"`
+		TransactionID int      `xml:"TransactionId,attr"`
+	}{
+		WhoisguardID:  d.Whoisguard.ID,
+		Years:         years,
+		Renew:         true,
+		ChargedAmount: amount,
+		OrderID:       s.newID(),
+		TransactionID: s.newID(),
+	}
+	return []interface{}{result}, nil
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// enabledString formats b the way the API reports enabled features.
+func enabledString(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
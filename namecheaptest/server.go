@@ -0,0 +1,383 @@
+// Package namecheaptest provides an in-process fake of the Namecheap API for
+// tests of code built on top of namecheap.Client.
+//
+// The fake keeps domains, DNS hosts, nameservers, Whoisguard subscriptions
+// and SSL certificates in memory and speaks the same XML protocol as the
+// real API, so a client pointed at it behaves as it would in production:
+//
+//	srv := namecheaptest.NewServer()
+//	defer srv.Close()
+//	srv.AddDomain("example.com")
+//
+//	client := namecheap.NewClient(srv.ApiUser, srv.ApiKey, srv.UserName,
+//		namecheap.WithBaseURL(srv.URL),
+//	)
+package namecheaptest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default credentials accepted by a new Server.
+const (
+	DefaultApiUser = "testuser"
+	DefaultApiKey  = "testkey"
+)
+
+// Error numbers returned by the fake. They match the ones of the real API.
+const (
+	ErrNumberInvalidApiKey     = 1011102
+	ErrNumberInvalidRequestIP  = 1011150
+	ErrNumberUnauthorizedUser  = 1016103
+	ErrNumberUnknownCommand    = 1010104
+	ErrNumberMissingParameter  = 2010324
+	ErrNumberInvalidParameter  = 2011166
+	ErrNumberDomainNotFound    = 2019166
+	ErrNumberDomainNotOwned    = 2016166
+	ErrNumberOrderFailed       = 2528166
+	ErrNumberDomainUnavailable = 3019166
+	ErrNumberNotUsingOurDNS    = 2030288
+)
+
+// dateFormat is the date format used by the API.
+const dateFormat = "01/02/2006"
+
+// Server is a fake Namecheap API listening on a local address. It is safe
+// for concurrent use. The exported fields may be changed before the first
+// request is made.
+type Server struct {
+	// URL is the base URL of the server, to be used as Client.BaseURL.
+	URL string
+
+	// ApiUser and ApiKey are the credentials the server accepts.
+	ApiUser string
+	ApiKey  string
+
+	// UserName is the account the domains belong to. Calls made on behalf
+	// of any other user name are rejected.
+	UserName string
+
+	// WhitelistedIPs, if not empty, restricts the ClientIp parameters
+	// accepted by the server.
+	WhitelistedIPs []string
+
+	// Balance is the account balance that billable commands are charged to.
+	Balance float64
+
+	// DomainPrice, WhoisguardPrice and SSLPrice are charged per year by
+	// the commands that buy the respective products.
+	DomainPrice     float64
+	WhoisguardPrice float64
+	SSLPrice        float64
+
+	// Now returns the current time of the server. It defaults to time.Now.
+	Now func() time.Time
+
+	server *httptest.Server
+
+	mu           sync.Mutex
+	domains      map[string]*Domain
+	taken        map[string]bool
+	certificates []*SSLCertificate
+	nextID       int
+	failures     []failure
+	commands     []string
+}
+
+// Domain is a domain registered in the account of the fake server.
+type Domain struct {
+	ID        int
+	Name      string
+	Created   time.Time
+	Expires   time.Time
+	IsLocked  bool
+	AutoRenew bool
+
+	// Nameservers holds custom nameservers. The domain uses the Namecheap
+	// DNS, and thus Hosts, when it is empty.
+	Nameservers []string
+	Hosts       []Host
+
+	// ChildNameservers maps the nameservers registered under the domain to
+	// their IP address.
+	ChildNameservers map[string]string
+
+	// Contacts holds the contact parameters last set for the domain, such
+	// as RegistrantFirstName.
+	Contacts url.Values
+
+	Whoisguard Whoisguard
+}
+
+// clone returns a deep copy of d.
+func (d *Domain) clone() Domain {
+	c := *d
+	c.Nameservers = append([]string(nil), d.Nameservers...)
+	c.Hosts = append([]Host(nil), d.Hosts...)
+	c.ChildNameservers = make(map[string]string, len(d.ChildNameservers))
+	for k, v := range d.ChildNameservers {
+		c.ChildNameservers[k] = v
+	}
+	c.Contacts = make(url.Values, len(d.Contacts))
+	for k, v := range d.Contacts {
+		c.Contacts[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// Host is a DNS record of a Domain.
+type Host struct {
+	ID      int
+	Name    string
+	Type    string
+	Address string
+	MXPref  int
+	TTL     int
+}
+
+// Whoisguard is the Whoisguard subscription of a Domain.
+type Whoisguard struct {
+	ID               int64
+	Enabled          bool
+	ForwardedToEmail string
+	Expires          time.Time
+}
+
+// SSLCertificate is an SSL certificate bought in the account.
+type SSLCertificate struct {
+	ID        int
+	Type      string
+	Years     int
+	HostName  string
+	Status    string
+	Purchased time.Time
+	Expires   time.Time
+}
+
+// failure is an injected error for the next call of a command.
+type failure struct {
+	command    string
+	statusCode int
+	number     int
+	message    string
+}
+
+// NewServer starts and returns a new Server with an empty account.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		ApiUser:         DefaultApiUser,
+		ApiKey:          DefaultApiKey,
+		UserName:        DefaultApiUser,
+		Balance:         1000,
+		DomainPrice:     8.88,
+		WhoisguardPrice: 2.88,
+		SSLPrice:        9,
+		Now:             time.Now,
+		domains:         make(map[string]*Domain),
+		taken:           make(map[string]bool),
+		nextID:          1000,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL + "/"
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// AddDomain registers name in the account for a year and returns a copy of it.
+func (s *Server) AddDomain(name string) Domain {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addDomain(strings.ToLower(name), 1).clone()
+}
+
+// Domain returns a copy of the domain called name, if it is in the account.
+func (s *Server) Domain(name string) (Domain, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.domains[strings.ToLower(name)]
+	if !ok {
+		return Domain{}, false
+	}
+	return d.clone(), true
+}
+
+// UpdateDomain calls update with the domain called name, if it is in the
+// account, to change its state.
+func (s *Server) UpdateDomain(name string, update func(*Domain)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.domains[strings.ToLower(name)]
+	if ok {
+		update(d)
+	}
+	return ok
+}
+
+// TakeDomain marks name as registered by somebody else, so that it is
+// reported unavailable and cannot be created.
+func (s *Server) TakeDomain(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taken[strings.ToLower(name)] = true
+}
+
+// Certificates returns copies of the SSL certificates of the account.
+func (s *Server) Certificates() []SSLCertificate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	certs := make([]SSLCertificate, len(s.certificates))
+	for i, cert := range s.certificates {
+		certs[i] = *cert
+	}
+	return certs
+}
+
+// Commands returns the commands received so far, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// FailNext makes the next call of command, or of any command if command is
+// empty, fail with an API error. Failures are used in the order they were
+// injected.
+func (s *Server) FailNext(command string, number int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{command: command, number: number, message: message})
+}
+
+// FailNextStatus makes the next call of command, or of any command if
+// command is empty, fail with the HTTP status code statusCode.
+func (s *Server) FailNextStatus(command string, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{command: command, statusCode: statusCode})
+}
+
+// apiError is an error returned in an API response.
+type apiError struct {
+	Number  int    `xml:"Number,attr"`
+	Message string `xml:",chardata"`
+}
+
+func errorf(number int, format string, args ...interface{}) *apiError {
+	return &apiError{Number: number, Message: fmt.Sprintf(format, args...)}
+}
+
+type apiResponse struct {
+	XMLName           xml.Name         `xml:"ApiResponse"`
+	Xmlns             string           `xml:"xmlns,attr"`
+	Status            string           `xml:"Status,attr"`
+	Errors            []apiError       `xml:"Errors>Error"`
+	Warnings          struct{}         `xml:"Warnings"`
+	RequestedCommand  string           `xml:"RequestedCommand"`
+	CommandResponse   *commandResponse `xml:"CommandResponse,omitempty"`
+	Server            string           `xml:"Server"`
+	GMTTimeDifference string           `xml:"GMTTimeDifference"`
+	ExecutionTime     string           `xml:"ExecutionTime"`
+}
+
+type commandResponse struct {
+	Type    string `xml:"Type,attr"`
+	Results []interface{}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p := r.Form
+	command := p.Get("Command")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, command)
+
+	resp := apiResponse{
+		Xmlns:             "http://api.namecheap.com/xml.response",
+		Status:            "OK",
+		RequestedCommand:  strings.ToLower(command),
+		Server:            "NAMECHEAPTEST",
+		GMTTimeDifference: "--0:00",
+		ExecutionTime:     "0.001",
+	}
+
+	var results []interface{}
+	var apiErr *apiError
+	if f, ok := s.nextFailure(command); ok {
+		if f.statusCode != 0 {
+			w.WriteHeader(f.statusCode)
+			return
+		}
+		apiErr = &apiError{Number: f.number, Message: f.message}
+	} else {
+		results, apiErr = s.handle(command, p)
+	}
+
+	if apiErr != nil {
+		resp.Status = "ERROR"
+		resp.Errors = []apiError{*apiErr}
+	} else {
+		resp.CommandResponse = &commandResponse{Type: command, Results: results}
+	}
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	fmt.Fprint(w, xml.Header)
+	// Encoding only fails when the client went away, which leaves no one to
+	// tell.
+	xml.NewEncoder(w).Encode(resp)
+}
+
+// nextFailure pops the first injected failure matching command.
+func (s *Server) nextFailure(command string) (failure, bool) {
+	for i, f := range s.failures {
+		if f.command == "" || f.command == command {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			return f, true
+		}
+	}
+	return failure{}, false
+}
+
+// handle authenticates and runs a command.
+func (s *Server) handle(command string, p url.Values) ([]interface{}, *apiError) {
+	if p.Get("ApiUser") != s.ApiUser || p.Get("ApiKey") != s.ApiKey {
+		return nil, errorf(ErrNumberInvalidApiKey, "API Key is invalid or API access has not been enabled")
+	}
+	if len(s.WhitelistedIPs) > 0 && !contains(s.WhitelistedIPs, p.Get("ClientIp")) {
+		return nil, errorf(ErrNumberInvalidRequestIP, "Invalid request IP: %s", p.Get("ClientIp"))
+	}
+	if p.Get("UserName") != s.UserName {
+		return nil, errorf(ErrNumberUnauthorizedUser, "Parameter UserName is unauthorized")
+	}
+
+	handler, ok := handlers[strings.ToLower(command)]
+	if !ok {
+		return nil, errorf(ErrNumberUnknownCommand, "Command %s is invalid", command)
+	}
+	return handler(s, p)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+package namecheaptest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	namecheap "github.com/billputer/go-namecheap"
+	"github.com/billputer/go-namecheap/namecheaptest"
+)
+
+func setup(t *testing.T) (*namecheaptest.Server, *namecheap.Client) {
+	t.Helper()
+	srv := namecheaptest.NewServer()
+	t.Cleanup(srv.Close)
+	client := namecheap.NewClient(srv.ApiUser, srv.ApiKey, srv.UserName,
+		namecheap.WithBaseURL(srv.URL),
+	)
+	client.NewRegistrant(
+		"Jane", "Doe",
+		"1 Main St", "",
+		"Springfield", "IL", "62701", "US",
+		"+1.5555555555", "jane@example.com",
+	)
+	return srv, client
+}
+
+func TestDomainLifecycle(t *testing.T) {
+	srv, client := setup(t)
+	srv.TakeDomain("taken.com")
+
+	checks, err := client.DomainsCheck("free.com", "taken.com")
+	if err != nil {
+		t.Fatalf("DomainsCheck returned error: %v", err)
+	}
+	if len(checks) != 2 || !checks[0].Available || checks[1].Available {
+		t.Errorf("DomainsCheck returned %+v, want free.com available and taken.com not", checks)
+	}
+
+	created, err := client.DomainCreate("free.com", 2)
+	if err != nil {
+		t.Fatalf("DomainCreate returned error: %v", err)
+	}
+	if !created.Registered || created.ChargedAmount != 2*srv.DomainPrice {
+		t.Errorf("DomainCreate returned %+v", created)
+	}
+	if _, err := client.DomainCreate("taken.com", 1); !errors.Is(err, namecheap.ErrDomainUnavailable) {
+		t.Errorf("DomainCreate of a taken domain returned %v, want ErrDomainUnavailable", err)
+	}
+
+	domains, err := client.DomainsGetList()
+	if err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if len(domains) != 1 || domains[0].Name != "free.com" || domains[0].ID != created.DomainID {
+		t.Errorf("DomainsGetList returned %+v", domains)
+	}
+
+	if _, err := client.DomainRenew("free.com", 1); err != nil {
+		t.Fatalf("DomainRenew returned error: %v", err)
+	}
+	d, ok := srv.Domain("free.com")
+	if !ok {
+		t.Fatal("Domain free.com is not in the account")
+	}
+	if got, want := d.Expires.Sub(d.Created).Hours()/24, 365.0*3; got < want {
+		t.Errorf("Domain expires after %v days, want at least %v", got, want)
+	}
+	if d.Contacts.Get("RegistrantFirstName") != "Jane" {
+		t.Errorf("Domain contacts = %v, want the registrant of the client", d.Contacts)
+	}
+
+	info, err := client.DomainGetInfo("free.com")
+	if err != nil {
+		t.Fatalf("DomainGetInfo returned error: %v", err)
+	}
+	if info.Name != "free.com" || !info.DNSDetails.IsUsingOurDNS {
+		t.Errorf("DomainGetInfo returned %+v", info)
+	}
+	if _, err := client.DomainGetInfo("missing.com"); !errors.Is(err, namecheap.ErrDomainNotFound) {
+		t.Errorf("DomainGetInfo of a missing domain returned %v, want ErrDomainNotFound", err)
+	}
+}
+
+func TestDNSHosts(t *testing.T) {
+	srv, client := setup(t)
+	srv.AddDomain("example.com")
+
+	hosts := []namecheap.DomainDNSHost{
+		{Name: "@", Type: "A", Address: "192.0.2.1", TTL: 1800},
+		{Name: "@", Type: "MX", Address: "mail.example.com", MXPref: 10, TTL: 1800},
+	}
+	if _, err := client.DomainDNSSetHosts("example", "com", hosts); err != nil {
+		t.Fatalf("DomainDNSSetHosts returned error: %v", err)
+	}
+	got, err := client.DomainsDNSGetHosts("example", "com")
+	if err != nil {
+		t.Fatalf("DomainsDNSGetHosts returned error: %v", err)
+	}
+	if len(got.Hosts) != len(hosts) {
+		t.Fatalf("DomainsDNSGetHosts returned %d hosts, want %d", len(got.Hosts), len(hosts))
+	}
+	for i, h := range got.Hosts {
+		if h.Name != hosts[i].Name || h.Type != hosts[i].Type || h.Address != hosts[i].Address || h.TTL != hosts[i].TTL {
+			t.Errorf("DomainsDNSGetHosts returned host %+v, want %+v", h, hosts[i])
+		}
+	}
+	if got.Hosts[1].MXPref != 10 {
+		t.Errorf("MX host has MXPref %d, want 10", got.Hosts[1].MXPref)
+	}
+
+	if _, err := client.DomainDNSSetCustom("example", "com", "ns1.example.net,ns2.example.net"); err != nil {
+		t.Fatalf("DomainDNSSetCustom returned error: %v", err)
+	}
+	_, err = client.DomainDNSSetHosts("example", "com", hosts)
+	if !namecheap.HasApiErrorNumber(err, namecheaptest.ErrNumberNotUsingOurDNS) {
+		t.Errorf("DomainDNSSetHosts with custom nameservers returned %v, want error %d", err, namecheaptest.ErrNumberNotUsingOurDNS)
+	}
+
+	if _, err := client.DomainsDNSGetHosts("other", "com"); !errors.Is(err, namecheap.ErrDomainNotOwned) {
+		t.Errorf("DomainsDNSGetHosts of another domain returned %v, want ErrDomainNotOwned", err)
+	}
+}
+
+func TestWhoisguard(t *testing.T) {
+	srv, client := setup(t)
+	d := srv.AddDomain("example.com")
+
+	if err := client.WhoisguardEnable(d.Whoisguard.ID, "jane@example.com"); err != nil {
+		t.Fatalf("WhoisguardEnable returned error: %v", err)
+	}
+	list, err := client.WhoisguardGetList()
+	if err != nil {
+		t.Fatalf("WhoisguardGetList returned error: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != d.Whoisguard.ID || list[0].Status != "enabled" {
+		t.Errorf("WhoisguardGetList returned %+v", list)
+	}
+
+	renew, err := client.WhoisguardRenew(d.Whoisguard.ID, 1)
+	if err != nil {
+		t.Fatalf("WhoisguardRenew returned error: %v", err)
+	}
+	if !renew.Renewed || renew.ChargedAmount != srv.WhoisguardPrice {
+		t.Errorf("WhoisguardRenew returned %+v", renew)
+	}
+
+	if err := client.WhoisguardDisable(d.Whoisguard.ID); err != nil {
+		t.Fatalf("WhoisguardDisable returned error: %v", err)
+	}
+	if d, _ := srv.Domain("example.com"); d.Whoisguard.Enabled {
+		t.Error("Whoisguard is still enabled after WhoisguardDisable")
+	}
+}
+
+func TestSSL(t *testing.T) {
+	srv, client := setup(t)
+
+	created, err := client.SslCreate("PositiveSSL", 1)
+	if err != nil {
+		t.Fatalf("SslCreate returned error: %v", err)
+	}
+	if len(created.SSLCertificate) != 1 {
+		t.Fatalf("SslCreate returned %+v", created)
+	}
+	id := created.SSLCertificate[0].CertificateID
+
+	if _, err := client.SslActivate(namecheap.SslActivateParams{
+		CertificateId:     id,
+		Csr:               "-----BEGIN CERTIFICATE REQUEST-----",
+		AdminEmailAddress: "jane@example.com",
+		WebServerType:     "nginx",
+		IsDNSDCValidation: true,
+	}); err != nil {
+		t.Fatalf("SslActivate returned error: %v", err)
+	}
+
+	certs, err := client.SslGetList()
+	if err != nil {
+		t.Fatalf("SslGetList returned error: %v", err)
+	}
+	if len(certs) != 1 || certs[0].CertificateID != id || certs[0].Status != "active" {
+		t.Errorf("SslGetList returned %+v", certs)
+	}
+	if got := srv.Certificates(); len(got) != 1 || got[0].Status != "active" {
+		t.Errorf("Server has certificates %+v", got)
+	}
+}
+
+func TestInsufficientFunds(t *testing.T) {
+	srv, client := setup(t)
+	srv.Balance = srv.DomainPrice
+
+	if _, err := client.DomainCreate("example.com", 2); !errors.Is(err, namecheap.ErrInsufficientFunds) {
+		t.Errorf("DomainCreate returned %v, want ErrInsufficientFunds", err)
+	}
+	if _, ok := srv.Domain("example.com"); ok {
+		t.Error("Domain was created without sufficient funds")
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	srv, _ := setup(t)
+	srv.WhitelistedIPs = []string{"192.0.2.1"}
+
+	bad := namecheap.NewClient(srv.ApiUser, "wrong", srv.UserName, namecheap.WithBaseURL(srv.URL))
+	if _, err := bad.DomainsGetList(); !errors.Is(err, namecheap.ErrInvalidCredentials) {
+		t.Errorf("DomainsGetList with a wrong key returned %v, want ErrInvalidCredentials", err)
+	}
+
+	client := namecheap.NewClient(srv.ApiUser, srv.ApiKey, srv.UserName,
+		namecheap.WithBaseURL(srv.URL),
+		namecheap.WithClientIP("198.51.100.1"),
+	)
+	if _, err := client.DomainsGetList(); !errors.Is(err, namecheap.ErrIPNotWhitelisted) {
+		t.Errorf("DomainsGetList from another IP returned %v, want ErrIPNotWhitelisted", err)
+	}
+	d, err := client.Diagnose(context.Background())
+	if err != nil {
+		t.Fatalf("Diagnose returned error: %v", err)
+	}
+	if d.ClientIp.Status != namecheap.CheckRejected || d.EgressIP != "198.51.100.1" || d.SeenIP != "198.51.100.1" {
+		t.Errorf("Diagnose returned %+v, want 198.51.100.1 rejected", d)
+	}
+
+	srv.WhitelistedIPs = append(srv.WhitelistedIPs, "198.51.100.1")
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping from a whitelisted IP returned error: %v", err)
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	srv, client := setup(t)
+	client.RetryPolicy = nil
+
+	srv.FailNext("namecheap.domains.getList", 500000, "Too many requests")
+	if _, err := client.DomainsGetList(); !errors.Is(err, namecheap.ErrRateLimited) {
+		t.Errorf("DomainsGetList returned %v, want ErrRateLimited", err)
+	}
+	srv.FailNextStatus("", http.StatusBadGateway)
+	if _, err := client.DomainsGetList(); err == nil {
+		t.Error("DomainsGetList returned no error for a bad gateway")
+	}
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Errorf("DomainsGetList returned %v after the injected failures", err)
+	}
+
+	want := []string{"namecheap.domains.getList", "namecheap.domains.getList", "namecheap.domains.getList"}
+	if got := srv.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Commands = %v, want %v", got, want)
+	}
+}
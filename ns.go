@@ -1,6 +1,9 @@
 package namecheap
 
-import "net/url"
+import (
+	"context"
+	"net/url"
+)
 
 const (
 	nsCreate  = "namecheap.domains.ns.create"
@@ -16,20 +19,33 @@ type DomainNSInfoResult struct {
 	Statuses   []string `xml:"NameserverStatuses>Status"`
 }
 
+// The CommandResponse elements the results of the commands are decoded from.
+type (
+	nsGetInfoResponse struct {
+		DomainNSInfo *DomainNSInfoResult `xml:"DomainNSInfoResult"`
+	}
+)
+
 func (client *Client) NSGetInfo(sld, tld, nameserver string) (*DomainNSInfoResult, error) {
+	return client.NSGetInfoContext(context.Background(), sld, tld, nameserver)
+}
+
+// NSGetInfoContext is like NSGetInfo but takes a context.
+func (client *Client) NSGetInfoContext(ctx context.Context, sld, tld, nameserver string) (*DomainNSInfoResult, error) {
+	var result nsGetInfoResponse
 	requestInfo := &ApiRequest{
 		command: nsGetInfo,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 	requestInfo.params.Set("SLD", sld)
 	requestInfo.params.Set("TLD", tld)
 	requestInfo.params.Set("Nameserver", nameserver)
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.DomainNSInfo, nil
+	return result.DomainNSInfo, nil
 }
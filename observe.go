@@ -0,0 +1,99 @@
+package namecheap
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Tracer creates a span for every API call made by a Client.
+// The namecheapotel package adapts OpenTelemetry to this interface.
+type Tracer interface {
+	// Start starts the span for a call to command. The returned context
+	// carries the span and is used for the rest of the call, down to the
+	// HTTP request.
+	Start(ctx context.Context, command string) (context.Context, Span)
+}
+
+// Span is the span of a single API call.
+type Span interface {
+	// End finishes the span with the outcome of the call.
+	End(info CallInfo)
+}
+
+// Observer is notified of the activity of a Client, typically to export
+// metrics. The namecheapprom package provides a Prometheus implementation.
+// Its methods may be called concurrently.
+type Observer interface {
+	// ObserveCall is called once for every finished call.
+	ObserveCall(info CallInfo)
+
+	// ObserveRetry is called every time a call to command is retried.
+	ObserveRetry(command string)
+
+	// ObserveRateLimitWait is called with the time every attempt of a call
+	// to command spent waiting for the client's RateLimiter.
+	ObserveRateLimitWait(command string, wait time.Duration)
+}
+
+// CallInfo summarizes a finished API call.
+type CallInfo struct {
+	Command string
+
+	// UserName is the account the call acted on behalf of.
+	UserName string
+
+	// Domain is the domain the call acted on, taken from the DomainName
+	// parameter or from SLD and TLD. It is empty for account wide calls.
+	Domain string
+	SLD    string
+	TLD    string
+
+	// StatusCode is the HTTP status code of the last attempt, or zero if
+	// no response was received.
+	StatusCode int
+
+	// Status is the status reported by the API, "OK" or "ERROR". It is
+	// empty if no API response was decoded.
+	Status string
+
+	// ApiErrors holds the numbers of the errors returned by the API.
+	ApiErrors []int
+
+	Latency time.Duration
+	Err     error
+}
+
+// newCallInfo summarizes the call described by inv.
+func newCallInfo(inv *Invocation, resp *ApiResponse, err error, latency time.Duration) CallInfo {
+	info := CallInfo{
+		Command:    inv.Command,
+		UserName:   inv.Params.Get("UserName"),
+		Domain:     paramsDomain(inv.Params),
+		SLD:        inv.Params.Get("SLD"),
+		TLD:        inv.Params.Get("TLD"),
+		StatusCode: inv.StatusCode,
+		ApiErrors:  ApiErrorNumbers(err),
+		Latency:    latency,
+		Err:        err,
+	}
+	switch {
+	case resp != nil:
+		info.Status = resp.Status
+	case len(info.ApiErrors) > 0:
+		info.Status = "ERROR"
+	}
+	return info
+}
+
+// paramsDomain returns the domain a call is about, from its DomainName
+// parameter or its SLD and TLD ones, or "" if it has none.
+func paramsDomain(p url.Values) string {
+	if domain := p.Get("DomainName"); domain != "" {
+		return domain
+	}
+	if sld, tld := p.Get("SLD"), p.Get("TLD"); sld != "" && tld != "" {
+		return sld + "." + tld
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+package namecheap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type spanKey struct{}
+
+type testTracer struct {
+	started []string
+	ended   []CallInfo
+}
+
+func (tracer *testTracer) Start(ctx context.Context, command string) (context.Context, Span) {
+	tracer.started = append(tracer.started, command)
+	return context.WithValue(ctx, spanKey{}, command), testSpan{tracer}
+}
+
+type testSpan struct {
+	tracer *testTracer
+}
+
+func (span testSpan) End(info CallInfo) {
+	span.tracer.ended = append(span.tracer.ended, info)
+}
+
+func TestTracer(t *testing.T) {
+	setup()
+	defer teardown()
+
+	tracer := &testTracer{}
+	client.Tracer = tracer
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2016166">Domain is not associated with your account</Error></Errors>
+</ApiResponse>`)
+	})
+
+	client.Interceptors = []Interceptor{
+		func(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error) {
+			if ctx.Value(spanKey{}) != inv.Command {
+				t.Errorf("Interceptor context does not carry the span of %v", inv.Command)
+			}
+			return next(ctx, inv)
+		},
+	}
+
+	_, err := client.DomainsDNSGetHostsContext(context.Background(), "domain", "com")
+	if err == nil {
+		t.Fatal("Expected error for error response")
+	}
+
+	if want := []string{domainsDNSGetHosts}; !reflect.DeepEqual(tracer.started, want) {
+		t.Errorf("Started spans %v, want %v", tracer.started, want)
+	}
+	if len(tracer.ended) != 1 {
+		t.Fatalf("Expected 1 ended span, got %d", len(tracer.ended))
+	}
+	info := tracer.ended[0]
+	info.Latency = 0
+	want := CallInfo{
+		Command:    domainsDNSGetHosts,
+		UserName:   "anUser",
+		Domain:     "domain.com",
+		SLD:        "domain",
+		TLD:        "com",
+		StatusCode: http.StatusOK,
+		Status:     "ERROR",
+		ApiErrors:  []int{2016166},
+		Err:        err,
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("Span ended with %+v, want %+v", info, want)
+	}
+}
+
+type testObserver struct {
+	mu      sync.Mutex
+	calls   []CallInfo
+	retries []string
+	waits   []string
+}
+
+func (observer *testObserver) ObserveCall(info CallInfo) {
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	observer.calls = append(observer.calls, info)
+}
+
+func (observer *testObserver) ObserveRetry(command string) {
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	observer.retries = append(observer.retries, command)
+}
+
+func (observer *testObserver) ObserveRateLimitWait(command string, wait time.Duration) {
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	observer.waits = append(observer.waits, command)
+}
+
+func TestObserver(t *testing.T) {
+	setup()
+	defer teardown()
+
+	observer := &testObserver{}
+	client.Observer = observer
+	client.RetryPolicy = testRetryPolicy
+	client.RateLimiter = NewRateLimiter(RateLimit{Window: time.Hour, Budget: 10})
+
+	attempts := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	if _, err := client.SslGetList(); err != nil {
+		t.Fatalf("SslGetList returned error: %v", err)
+	}
+
+	if want := []string{sslGetList}; !reflect.DeepEqual(observer.retries, want) {
+		t.Errorf("Observed retries %v, want %v", observer.retries, want)
+	}
+	if want := []string{sslGetList, sslGetList}; !reflect.DeepEqual(observer.waits, want) {
+		t.Errorf("Observed rate limit waits %v, want %v", observer.waits, want)
+	}
+	if len(observer.calls) != 1 || observer.calls[0].Command != sslGetList || observer.calls[0].Status != "OK" {
+		t.Errorf("Observed calls %+v, want one successful %v", observer.calls, sslGetList)
+	}
+}
@@ -0,0 +1,230 @@
+package namecheap
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by NewClientFromEnv.
+const (
+	EnvApiUser    = "NAMECHEAP_API_USER"
+	EnvApiKey     = "NAMECHEAP_API_KEY"
+	EnvApiKeyFile = "NAMECHEAP_API_KEY_FILE"
+	EnvUserName   = "NAMECHEAP_USERNAME"
+	EnvClientIp   = "NAMECHEAP_CLIENT_IP"
+	EnvSandbox    = "NAMECHEAP_SANDBOX"
+	EnvTimeout    = "NAMECHEAP_TIMEOUT"
+)
+
+// Option configures a Client built by NewClient.
+type Option func(*Client)
+
+// WithSandbox points the client at the Namecheap sandbox API.
+func WithSandbox() Option {
+	return WithBaseURL(sandboxBaseURL)
+}
+
+// WithBaseURL points the client at a different API endpoint.
+func WithBaseURL(baseURL string) Option {
+	return func(client *Client) {
+		client.BaseURL = baseURL
+	}
+}
+
+// WithHTTPClient makes the client send its requests through httpClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.HttpClient = httpClient
+	}
+}
+
+// WithClientIP sets the ClientIp parameter sent with every request.
+// It must be the IP address whitelisted for the API user. It replaces the
+// IPDetector set by the options before it.
+func WithClientIP(ip string) Option {
+	return func(client *Client) {
+		client.ClientIp = ip
+		client.IPDetector = nil
+		client.ipFollowsEgress = false
+	}
+}
+
+// WithIPDetector makes the client detect its ClientIp with detector. See
+// Client.IPDetector.
+func WithIPDetector(detector IPDetector) Option {
+	return func(client *Client) {
+		client.IPDetector = detector
+		client.ipFollowsEgress = false
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(client *Client) {
+		client.UserAgent = userAgent
+	}
+}
+
+// WithTimeout bounds the duration of every call. See Client.Timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(client *Client) {
+		client.Timeout = timeout
+	}
+}
+
+// WithRetryPolicy makes the client retry failed calls according to policy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(client *Client) {
+		client.RetryPolicy = &policy
+	}
+}
+
+// WithRateLimiter paces the calls of the client with limiter.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(client *Client) {
+		client.RateLimiter = limiter
+	}
+}
+
+// WithCircuitBreaker makes the client stop sending requests while the API
+// keeps failing, as decided by breaker.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(client *Client) {
+		client.CircuitBreaker = breaker
+	}
+}
+
+// WithInterceptors appends interceptors to the chain of the client.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(client *Client) {
+		client.Interceptors = append(client.Interceptors, interceptors...)
+	}
+}
+
+// WithLogger makes the client log every call to logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(client *Client) {
+		client.Logger = logger
+	}
+}
+
+// WithTracer makes the client create a span for every call with tracer.
+func WithTracer(tracer Tracer) Option {
+	return func(client *Client) {
+		client.Tracer = tracer
+	}
+}
+
+// WithObserver makes the client report its activity to observer.
+func WithObserver(observer Observer) Option {
+	return func(client *Client) {
+		client.Observer = observer
+	}
+}
+
+// WithDryRun puts the client in dry-run mode, recording the requests of
+// mutating commands in recorder instead of sending them.
+func WithDryRun(recorder DryRunRecorder) Option {
+	return func(client *Client) {
+		client.DryRun = recorder
+	}
+}
+
+// WithAudit makes the client record the calls that change the account in
+// sink.
+func WithAudit(sink AuditSink) Option {
+	return func(client *Client) {
+		client.Audit = sink
+	}
+}
+
+// WithAuditErrorHandler makes the client call handler with the records its
+// AuditSink failed to record. See Client.OnAuditError.
+func WithAuditErrorHandler(handler func(ctx context.Context, rec AuditRecord, err error)) Option {
+	return func(client *Client) {
+		client.OnAuditError = handler
+	}
+}
+
+// WithCredentials makes the client get the API key of every request from
+// provider. See Client.Credentials.
+func WithCredentials(provider CredentialsProvider) Option {
+	return func(client *Client) {
+		client.Credentials = provider
+	}
+}
+
+// WithCache makes the client answer calls of read-only commands from cache
+// when it can.
+func WithCache(cache *Cache) Option {
+	return func(client *Client) {
+		client.Cache = cache
+	}
+}
+
+// NewClientFromEnv returns a client configured from the NAMECHEAP_*
+// environment variables:
+//
+//	NAMECHEAP_API_USER      API user (required)
+//	NAMECHEAP_API_KEY       API key (required unless a key file is set)
+//	NAMECHEAP_API_KEY_FILE  file holding the API key, read again when it changes
+//	NAMECHEAP_USERNAME      user to act as, defaults to the API user
+//	NAMECHEAP_CLIENT_IP     whitelisted IP address of the caller, or "auto"
+//	                        to detect it with DefaultIPEchoURL
+//	NAMECHEAP_SANDBOX       use the sandbox API when true
+//	NAMECHEAP_TIMEOUT       per call timeout, such as "30s"
+//
+// The options given are applied after the environment, so they take
+// precedence over it.
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	apiUser := os.Getenv(EnvApiUser)
+	if apiUser == "" {
+		return nil, fmt.Errorf("%s is not set", EnvApiUser)
+	}
+
+	var envOpts []Option
+	apiKey := os.Getenv(EnvApiKey)
+	if apiKey == "" {
+		path := os.Getenv(EnvApiKeyFile)
+		if path == "" {
+			return nil, fmt.Errorf("%s is not set", EnvApiKey)
+		}
+		envOpts = append(envOpts, WithCredentials(NewFileCredentials(path)))
+	}
+	userName := os.Getenv(EnvUserName)
+	if userName == "" {
+		userName = apiUser
+	}
+
+	switch ip := os.Getenv(EnvClientIp); ip {
+	case "":
+	case "auto":
+		detector := NewCachedIPDetector(NewHTTPEchoDetector(DefaultIPEchoURL), DefaultIPDetectionTTL)
+		envOpts = append(envOpts, WithIPDetector(detector))
+	default:
+		envOpts = append(envOpts, WithClientIP(ip))
+	}
+	if v := os.Getenv(EnvSandbox); v != "" {
+		sandbox, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", EnvSandbox, err)
+		}
+		if sandbox {
+			envOpts = append(envOpts, WithSandbox())
+		}
+	}
+	if v := os.Getenv(EnvTimeout); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", EnvTimeout, err)
+		}
+		envOpts = append(envOpts, WithTimeout(timeout))
+	}
+
+	return NewClient(apiUser, apiKey, userName, append(envOpts, opts...)...), nil
+}
@@ -0,0 +1,130 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientOptions(t *testing.T) {
+	httpClient := &http.Client{}
+	limiter := NewRateLimiter()
+	c := NewClient("anApiUser", "anToken", "anUser",
+		WithSandbox(),
+		WithHTTPClient(httpClient),
+		WithClientIP("10.0.0.1"),
+		WithUserAgent("test-agent/1.0"),
+		WithTimeout(time.Minute),
+		WithRetryPolicy(DefaultRetryPolicy),
+		WithRateLimiter(limiter),
+	)
+
+	if c.BaseURL != sandboxBaseURL {
+		t.Errorf("BaseURL = %v, want %v", c.BaseURL, sandboxBaseURL)
+	}
+	if c.HttpClient != httpClient {
+		t.Errorf("HttpClient = %v, want %v", c.HttpClient, httpClient)
+	}
+	if c.ClientIp != "10.0.0.1" {
+		t.Errorf("ClientIp = %v, want %v", c.ClientIp, "10.0.0.1")
+	}
+	if c.UserAgent != "test-agent/1.0" {
+		t.Errorf("UserAgent = %v, want %v", c.UserAgent, "test-agent/1.0")
+	}
+	if c.Timeout != time.Minute {
+		t.Errorf("Timeout = %v, want %v", c.Timeout, time.Minute)
+	}
+	if c.RetryPolicy == nil || *c.RetryPolicy != DefaultRetryPolicy {
+		t.Errorf("RetryPolicy = %+v, want %+v", c.RetryPolicy, DefaultRetryPolicy)
+	}
+	if c.RateLimiter != limiter {
+		t.Errorf("RateLimiter = %v, want %v", c.RateLimiter, limiter)
+	}
+}
+
+func TestUserAgentAndTimeout(t *testing.T) {
+	setup()
+	defer teardown()
+	client.UserAgent = "test-agent/1.0"
+	client.Timeout = 20 * time.Millisecond
+
+	done := make(chan struct{})
+	defer close(done)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "test-agent/1.0" {
+			t.Errorf("User-Agent = %v, want %v", ua, "test-agent/1.0")
+		}
+		<-done
+	})
+
+	_, err := client.DomainsGetList()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(EnvApiUser, "envApiUser")
+	t.Setenv(EnvApiKey, "envKey")
+	t.Setenv(EnvUserName, "")
+	t.Setenv(EnvClientIp, "10.0.0.2")
+	t.Setenv(EnvSandbox, "true")
+	t.Setenv(EnvTimeout, "45s")
+
+	c, err := NewClientFromEnv(WithClientIP("10.0.0.3"))
+	if err != nil {
+		t.Fatalf("NewClientFromEnv returned error: %v", err)
+	}
+
+	if c.ApiUser != "envApiUser" || c.ApiToken != "envKey" || c.UserName != "envApiUser" {
+		t.Errorf("NewClientFromEnv credentials = %v/%v/%v, want envApiUser/envKey/envApiUser",
+			c.ApiUser, c.ApiToken, c.UserName)
+	}
+	if c.ClientIp != "10.0.0.3" {
+		t.Errorf("ClientIp = %v, want the option to override the environment", c.ClientIp)
+	}
+	if c.BaseURL != sandboxBaseURL {
+		t.Errorf("BaseURL = %v, want %v", c.BaseURL, sandboxBaseURL)
+	}
+	if c.Timeout != 45*time.Second {
+		t.Errorf("Timeout = %v, want %v", c.Timeout, 45*time.Second)
+	}
+}
+
+func TestNewClientFromEnvErrors(t *testing.T) {
+	tests := []struct {
+		env     map[string]string
+		wantErr string
+	}{
+		{map[string]string{EnvApiUser: "", EnvApiKey: "key"}, EnvApiUser + " is not set"},
+		{map[string]string{EnvApiUser: "user", EnvApiKey: ""}, EnvApiKey + " is not set"},
+		{map[string]string{EnvApiUser: "user", EnvApiKey: "key", EnvSandbox: "maybe"}, "invalid " + EnvSandbox},
+		{map[string]string{EnvApiUser: "user", EnvApiKey: "key", EnvTimeout: "soon"}, "invalid " + EnvTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantErr, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := NewClientFromEnv()
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("NewClientFromEnv error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func ExampleNewClient() {
+	client := NewClient("apiUser", "apiToken", "userName",
+		WithSandbox(),
+		WithClientIP("203.0.113.10"),
+		WithTimeout(30*time.Second),
+	)
+	fmt.Println(client.BaseURL)
+	// Output: https://api.sandbox.namecheap.com/xml.response
+}
@@ -0,0 +1,257 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDomainNotInPool is returned by a Pool for domains that none of its
+// accounts holds.
+var ErrDomainNotInPool = errors.New("domain is not held by any account of the pool")
+
+// poolPageSize is the number of domains a Pool lists per request, the most
+// the API allows.
+const poolPageSize = 100
+
+// Pool routes the calls about a domain to the client of the account that
+// holds it, among the clients of several accounts. Which account holds
+// which domain is discovered by listing the domains of every account, the
+// first time a domain is looked up and whenever one is not found. The
+// lists are always asked to the API, never taken from the Cache of the
+// clients. An account whose domains cannot be listed keeps the domains
+// found by the previous discovery, and does not keep the domains of the
+// other accounts from being found. A Pool is safe for concurrent use.
+type Pool struct {
+	clients []*Client
+
+	// MinRefreshInterval is the least time between two discoveries
+	// started by lookups of unknown domains, so that looking up domains
+	// that no account holds does not use up the API quota. Refresh is not
+	// limited by it.
+	MinRefreshInterval time.Duration
+
+	// refreshMu serializes discoveries.
+	refreshMu sync.Mutex
+	mu        sync.Mutex
+	owners    map[string]*Client
+	refreshed time.Time
+	now       func() time.Time
+}
+
+// NewPool returns a Pool of the given clients, each one for a different
+// account. When several accounts hold the same domain, the first one
+// given wins.
+func NewPool(clients ...*Client) *Pool {
+	return &Pool{
+		clients:            append([]*Client(nil), clients...),
+		MinRefreshInterval: time.Minute,
+		owners:             make(map[string]*Client),
+		now:                time.Now,
+	}
+}
+
+// Clients returns the clients of the pool.
+func (p *Pool) Clients() []*Client {
+	return append([]*Client(nil), p.clients...)
+}
+
+// ClientFor returns the client of the account that holds domainName. The
+// error wraps ErrDomainNotInPool if no account holds it.
+func (p *Pool) ClientFor(ctx context.Context, domainName string) (*Client, error) {
+	key := strings.ToLower(domainName)
+	p.mu.Lock()
+	client, ok := p.owners[key]
+	p.mu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	// The domain may be held by one of the accounts that could be listed,
+	// so the error of the others only matters if it is not.
+	refreshErr := p.refresh(ctx, false)
+	p.mu.Lock()
+	client, ok = p.owners[key]
+	p.mu.Unlock()
+	if !ok {
+		err := fmt.Errorf("%w: %s", ErrDomainNotInPool, domainName)
+		if refreshErr != nil {
+			err = errors.Join(err, refreshErr)
+		}
+		return nil, err
+	}
+	return client, nil
+}
+
+// Refresh discovers again which account holds which domain. The error
+// joins the errors of the accounts whose domains could not be listed, the
+// domains of the others being discovered anyway.
+func (p *Pool) Refresh(ctx context.Context) error {
+	return p.refresh(ctx, true)
+}
+
+// refresh lists the domains of every account, unless force is false and
+// the last discovery is more recent than MinRefreshInterval. The accounts
+// that fail to be listed keep the domains they held.
+func (p *Pool) refresh(ctx context.Context, force bool) error {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	p.mu.Lock()
+	recent := !p.refreshed.IsZero() && p.now().Sub(p.refreshed) < p.MinRefreshInterval
+	p.mu.Unlock()
+	if recent && !force {
+		return nil
+	}
+
+	p.mu.Lock()
+	previous := p.owners
+	p.mu.Unlock()
+
+	owners := make(map[string]*Client)
+	var errs []error
+	for _, client := range p.clients {
+		domains, err := listDomains(ctx, client)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing the domains of %s: %w", client.UserName, err))
+			for key, owner := range previous {
+				if owner == client {
+					domains = append(domains, DomainGetListResult{Name: key})
+				}
+			}
+		}
+		for _, domain := range domains {
+			key := strings.ToLower(domain.Name)
+			if _, ok := owners[key]; !ok {
+				owners[key] = client
+			}
+		}
+	}
+
+	p.mu.Lock()
+	p.owners, p.refreshed = owners, p.now()
+	p.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// listDomains returns every domain of the account of client.
+func listDomains(ctx context.Context, client *Client) ([]DomainGetListResult, error) {
+	var all []DomainGetListResult
+	for page := 1; ; page++ {
+		domains, total, err := client.domainsGetListPage(ctx, page, poolPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, domains...)
+		if len(domains) == 0 || page*poolPageSize >= total {
+			return all, nil
+		}
+	}
+}
+
+// route calls fn with the client of the account that holds domainName.
+// If the account turns out not to hold it anymore, for example because it
+// was moved to another account, ownership is discovered again and the
+// call is made once more with the new owner.
+func (p *Pool) route(ctx context.Context, domainName string, fn func(*Client) error) error {
+	client, err := p.ClientFor(ctx, domainName)
+	if err != nil {
+		return err
+	}
+	err = fn(client)
+	if !errors.Is(err, ErrDomainNotOwned) && !errors.Is(err, ErrDomainNotFound) {
+		return err
+	}
+
+	// The domain may have moved to an account that could be listed even if
+	// others could not.
+	p.Refresh(ctx)
+	p.mu.Lock()
+	owner, ok := p.owners[strings.ToLower(domainName)]
+	p.mu.Unlock()
+	if !ok || owner == client {
+		return err
+	}
+	return fn(owner)
+}
+
+func (p *Pool) DomainGetInfo(domainName string) (*DomainInfo, error) {
+	return p.DomainGetInfoContext(context.Background(), domainName)
+}
+
+// DomainGetInfoContext is like DomainGetInfo but takes a context.
+func (p *Pool) DomainGetInfoContext(ctx context.Context, domainName string) (info *DomainInfo, err error) {
+	err = p.route(ctx, domainName, func(client *Client) (err error) {
+		info, err = client.DomainGetInfoContext(ctx, domainName)
+		return err
+	})
+	return info, err
+}
+
+func (p *Pool) DomainRenew(domainName string, years int) (*DomainRenewResult, error) {
+	return p.DomainRenewContext(context.Background(), domainName, years)
+}
+
+// DomainRenewContext is like DomainRenew but takes a context.
+func (p *Pool) DomainRenewContext(ctx context.Context, domainName string, years int) (result *DomainRenewResult, err error) {
+	err = p.route(ctx, domainName, func(client *Client) (err error) {
+		result, err = client.DomainRenewContext(ctx, domainName, years)
+		return err
+	})
+	return result, err
+}
+
+func (p *Pool) DomainSetContacts(domainName string, options ...DomainSetContactsOption) (*DomainSetContactsResult, error) {
+	return p.DomainSetContactsContext(context.Background(), domainName, options...)
+}
+
+// DomainSetContactsContext is like DomainSetContacts but takes a context.
+func (p *Pool) DomainSetContactsContext(ctx context.Context, domainName string, options ...DomainSetContactsOption) (result *DomainSetContactsResult, err error) {
+	err = p.route(ctx, domainName, func(client *Client) (err error) {
+		result, err = client.DomainSetContactsContext(ctx, domainName, options...)
+		return err
+	})
+	return result, err
+}
+
+func (p *Pool) DomainsDNSGetHosts(sld, tld string) (*DomainDNSGetHostsResult, error) {
+	return p.DomainsDNSGetHostsContext(context.Background(), sld, tld)
+}
+
+// DomainsDNSGetHostsContext is like DomainsDNSGetHosts but takes a context.
+func (p *Pool) DomainsDNSGetHostsContext(ctx context.Context, sld, tld string) (result *DomainDNSGetHostsResult, err error) {
+	err = p.route(ctx, sld+"."+tld, func(client *Client) (err error) {
+		result, err = client.DomainsDNSGetHostsContext(ctx, sld, tld)
+		return err
+	})
+	return result, err
+}
+
+func (p *Pool) DomainDNSSetHosts(sld, tld string, hosts []DomainDNSHost) (*DomainDNSSetHostsResult, error) {
+	return p.DomainDNSSetHostsContext(context.Background(), sld, tld, hosts)
+}
+
+// DomainDNSSetHostsContext is like DomainDNSSetHosts but takes a context.
+func (p *Pool) DomainDNSSetHostsContext(ctx context.Context, sld, tld string, hosts []DomainDNSHost) (result *DomainDNSSetHostsResult, err error) {
+	err = p.route(ctx, sld+"."+tld, func(client *Client) (err error) {
+		result, err = client.DomainDNSSetHostsContext(ctx, sld, tld, hosts)
+		return err
+	})
+	return result, err
+}
+
+func (p *Pool) DomainDNSSetCustom(sld, tld, nameservers string) (*DomainDNSSetCustomResult, error) {
+	return p.DomainDNSSetCustomContext(context.Background(), sld, tld, nameservers)
+}
+
+// DomainDNSSetCustomContext is like DomainDNSSetCustom but takes a context.
+func (p *Pool) DomainDNSSetCustomContext(ctx context.Context, sld, tld, nameservers string) (result *DomainDNSSetCustomResult, err error) {
+	err = p.route(ctx, sld+"."+tld, func(client *Client) (err error) {
+		result, err = client.DomainDNSSetCustomContext(ctx, sld, tld, nameservers)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,232 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// poolServer serves the domains of several accounts, keyed by API user,
+// counting the getList requests it receives. The accounts of broken are
+// rejected as if their API key was invalid.
+type poolServer struct {
+	mu       sync.Mutex
+	accounts map[string][]string
+	broken   map[string]bool
+	lists    int
+}
+
+func (s *poolServer) move(domain, from, to string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, name := range s.accounts[from] {
+		if name == domain {
+			s.accounts[from] = append(s.accounts[from][:i], s.accounts[from][i+1:]...)
+			break
+		}
+	}
+	s.accounts[to] = append(s.accounts[to], domain)
+}
+
+func (s *poolServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken[r.FormValue("ApiUser")] {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="1011102">API Key is invalid or API access has not been enabled</Error></Errors>
+</ApiResponse>`)
+		return
+	}
+	domains := s.accounts[r.FormValue("ApiUser")]
+	command := r.FormValue("Command")
+	var result string
+	switch command {
+	case domainsGetList:
+		s.lists++
+		page, _ := strconv.Atoi(r.FormValue("Page"))
+		pageSize, _ := strconv.Atoi(r.FormValue("PageSize"))
+		var b strings.Builder
+		for i := (page - 1) * pageSize; i < len(domains) && i < page*pageSize; i++ {
+			fmt.Fprintf(&b, `<Domain ID="%d" Name="%s" />`, i+1, domains[i])
+		}
+		result = fmt.Sprintf(`<DomainGetListResult>%s</DomainGetListResult>
+    <Paging><TotalItems>%d</TotalItems><CurrentPage>%d</CurrentPage><PageSize>%d</PageSize></Paging>`,
+			b.String(), len(domains), page, pageSize)
+	default:
+		domain := r.FormValue("DomainName")
+		if domain == "" {
+			domain = r.FormValue("SLD") + "." + r.FormValue("TLD")
+		}
+		owned := false
+		for _, name := range domains {
+			owned = owned || strings.EqualFold(name, domain)
+		}
+		if !owned {
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="ERROR" xmlns="http://api.namecheap.com/xml.response">
+  <Errors><Error Number="2016166">Domain is not associated with your account</Error></Errors>
+</ApiResponse>`)
+			return
+		}
+		switch command {
+		case domainsGetInfo:
+			result = fmt.Sprintf(`<DomainGetInfoResult DomainName="%s" OwnerName="%s" />`, domain, r.FormValue("ApiUser"))
+		case domainsDNSGetHosts:
+			result = fmt.Sprintf(`<DomainDNSGetHostsResult Domain="%s" IsUsingOurDNS="true" />`, domain)
+		}
+	}
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <CommandResponse Type="%s">
+    %s
+  </CommandResponse>
+</ApiResponse>`, command, result)
+}
+
+func TestPool(t *testing.T) {
+	setup()
+	defer teardown()
+
+	srv := &poolServer{accounts: map[string][]string{"second": {"other.com"}}}
+	for i := 0; i < 150; i++ {
+		srv.accounts["first"] = append(srv.accounts["first"], fmt.Sprintf("domain%d.com", i))
+	}
+	mux.Handle("/", srv)
+
+	first := NewClient("first", "firstKey", "first", WithBaseURL(client.BaseURL))
+	second := NewClient("second", "secondKey", "second", WithBaseURL(client.BaseURL))
+	pool := NewPool(first, second)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	info, err := pool.DomainGetInfo("Domain120.com")
+	if err != nil {
+		t.Fatalf("DomainGetInfo returned error: %v", err)
+	}
+	if info.Name != "Domain120.com" || info.Owner != "first" {
+		t.Errorf("DomainGetInfo returned %+v, want Domain120.com of first", info)
+	}
+	hosts, err := pool.DomainsDNSGetHosts("other", "com")
+	if err != nil {
+		t.Fatalf("DomainsDNSGetHosts returned error: %v", err)
+	}
+	if hosts.Domain != "other.com" {
+		t.Errorf("DomainsDNSGetHosts returned %+v", hosts)
+	}
+	if c, err := pool.ClientFor(context.Background(), "domain0.com"); err != nil || c != first {
+		t.Errorf("ClientFor returned %v, %v, want the first client", c, err)
+	}
+	if srv.lists != 3 {
+		t.Errorf("Listed %d pages of domains, want 3", srv.lists)
+	}
+
+	// Unknown domains are looked for again at most once per interval.
+	for i := 0; i < 2; i++ {
+		if _, err := pool.DomainGetInfo("unknown.com"); !errors.Is(err, ErrDomainNotInPool) {
+			t.Errorf("DomainGetInfo of an unknown domain returned %v, want ErrDomainNotInPool", err)
+		}
+	}
+	if srv.lists != 3 {
+		t.Errorf("Listed %d pages of domains, want no more within the interval", srv.lists)
+	}
+	now = now.Add(pool.MinRefreshInterval)
+	if _, err := pool.DomainGetInfo("unknown.com"); !errors.Is(err, ErrDomainNotInPool) {
+		t.Errorf("DomainGetInfo of an unknown domain returned %v, want ErrDomainNotInPool", err)
+	}
+	if srv.lists != 6 {
+		t.Errorf("Listed %d pages of domains, want 6 after the interval", srv.lists)
+	}
+
+	// Domains moved to another account are found there.
+	srv.move("other.com", "second", "first")
+	info, err = pool.DomainGetInfo("other.com")
+	if err != nil {
+		t.Fatalf("DomainGetInfo of a moved domain returned error: %v", err)
+	}
+	if info.Owner != "first" {
+		t.Errorf("DomainGetInfo of a moved domain returned %+v, want it from first", info)
+	}
+	if c, _ := pool.ClientFor(context.Background(), "other.com"); c != first {
+		t.Errorf("ClientFor of a moved domain returned %v, want the first client", c)
+	}
+
+	// Domains no account holds anymore fail with the error of the API.
+	srv.move("other.com", "first", "nobody")
+	if _, err := pool.DomainGetInfo("other.com"); !errors.Is(err, ErrDomainNotOwned) {
+		t.Errorf("DomainGetInfo of a domain gone from the pool returned %v, want ErrDomainNotOwned", err)
+	}
+}
+
+func TestPoolSkipsCache(t *testing.T) {
+	setup()
+	defer teardown()
+
+	srv := &poolServer{accounts: map[string][]string{"first": {"example.com"}, "second": {"other.com"}}}
+	mux.Handle("/", srv)
+
+	cache := NewCache(nil)
+	first := NewClient("first", "firstKey", "first", WithBaseURL(client.BaseURL), WithCache(cache))
+	second := NewClient("second", "secondKey", "second", WithBaseURL(client.BaseURL), WithCache(cache))
+	pool := NewPool(first, second)
+
+	if _, err := pool.DomainGetInfo("example.com"); err != nil {
+		t.Fatalf("DomainGetInfo returned error: %v", err)
+	}
+	srv.move("example.com", "first", "second")
+	info, err := pool.DomainGetInfo("example.com")
+	if err != nil {
+		t.Fatalf("DomainGetInfo of a moved domain returned error: %v", err)
+	}
+	if info.Owner != "second" {
+		t.Errorf("DomainGetInfo of a moved domain returned %+v, want it from second", info)
+	}
+	if srv.lists != 4 {
+		t.Errorf("Listed %d pages of domains, want 4 with none from the cache", srv.lists)
+	}
+}
+
+func TestPoolBrokenAccount(t *testing.T) {
+	setup()
+	defer teardown()
+
+	srv := &poolServer{
+		accounts: map[string][]string{"first": {"example.com"}, "second": {"other.com"}},
+		broken:   map[string]bool{},
+	}
+	mux.Handle("/", srv)
+
+	first := NewClient("first", "firstKey", "first", WithBaseURL(client.BaseURL))
+	second := NewClient("second", "secondKey", "second", WithBaseURL(client.BaseURL))
+	pool := NewPool(first, second)
+
+	if err := pool.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+
+	// The domains of a broken account are kept, and those of the others are
+	// still discovered.
+	srv.mu.Lock()
+	srv.broken["first"] = true
+	srv.accounts["second"] = append(srv.accounts["second"], "new.com")
+	srv.mu.Unlock()
+	if err := pool.Refresh(context.Background()); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Refresh with a broken account returned %v, want ErrInvalidCredentials", err)
+	}
+	for domain, want := range map[string]*Client{"example.com": first, "new.com": second} {
+		if c, err := pool.ClientFor(context.Background(), domain); err != nil || c != want {
+			t.Errorf("ClientFor(%q) returned %v, %v, want %v", domain, c, err, want.UserName)
+		}
+	}
+
+	pool.now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, err := pool.ClientFor(context.Background(), "unknown.com")
+	if !errors.Is(err, ErrDomainNotInPool) || !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("ClientFor of an unknown domain returned %v, want ErrDomainNotInPool and the error of the broken account", err)
+	}
+}
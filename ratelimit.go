@@ -0,0 +1,148 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitExceeded is returned when a call would have to wait for rate
+// limit budget beyond the deadline of its context.
+var ErrRateLimitExceeded = errors.New("rate limit budget exhausted before context deadline")
+
+// RateLimit allows Budget calls within any sliding Window.
+// A Budget of zero or less leaves the window unlimited.
+type RateLimit struct {
+	Window time.Duration
+	Budget int
+}
+
+// DefaultRateLimits are the quotas Namecheap applies to API accounts.
+var DefaultRateLimits = []RateLimit{
+	{Window: time.Minute, Budget: 20},
+	{Window: time.Hour, Budget: 700},
+	{Window: 24 * time.Hour, Budget: 8000},
+}
+
+// RateLimitStatus reports the state of a single RateLimit window.
+type RateLimitStatus struct {
+	Window    time.Duration
+	Budget    int
+	Remaining int
+	// Reset is when the next slot in the window frees up.
+	// It is the zero time when the window has remaining budget.
+	Reset time.Time
+}
+
+// RateLimiter paces calls so that they stay within a set of RateLimit
+// windows. It is safe for concurrent use and can be shared by several
+// clients that use the same API account.
+type RateLimiter struct {
+	mu     sync.Mutex
+	limits []RateLimit
+	// calls holds, per limit, the times of the calls made within its window.
+	calls [][]time.Time
+	now   func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter enforcing limits,
+// or DefaultRateLimits if none are given.
+func NewRateLimiter(limits ...RateLimit) *RateLimiter {
+	if len(limits) == 0 {
+		limits = DefaultRateLimits
+	}
+	return &RateLimiter{
+		limits: append([]RateLimit(nil), limits...),
+		calls:  make([][]time.Time, len(limits)),
+		now:    time.Now,
+	}
+}
+
+// Wait blocks until every window has budget for one more call and then
+// consumes it. It returns early with the context's error if ctx is done,
+// or with ErrRateLimitExceeded if the budget would only become available
+// after the context's deadline.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := l.now()
+		delay := l.reserve(now)
+		l.mu.Unlock()
+		if delay <= 0 {
+			return nil
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+			return ErrRateLimitExceeded
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Remaining returns the status of every window.
+func (l *RateLimiter) Remaining() []RateLimitStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	statuses := make([]RateLimitStatus, len(l.limits))
+	for i, limit := range l.limits {
+		calls := l.prune(i, now)
+		statuses[i] = RateLimitStatus{
+			Window:    limit.Window,
+			Budget:    limit.Budget,
+			Remaining: limit.Budget - len(calls),
+		}
+		if limit.Budget > 0 && statuses[i].Remaining <= 0 {
+			statuses[i].Remaining = 0
+			statuses[i].Reset = calls[len(calls)-limit.Budget].Add(limit.Window)
+		}
+	}
+	return statuses
+}
+
+// reserve records a call at now if every window allows it and returns zero.
+// Otherwise nothing is recorded and the time until a call could be allowed
+// is returned.
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	var delay time.Duration
+	for i, limit := range l.limits {
+		calls := l.prune(i, now)
+		if limit.Budget <= 0 || len(calls) < limit.Budget {
+			continue
+		}
+		if d := calls[len(calls)-limit.Budget].Add(limit.Window).Sub(now); d > delay {
+			delay = d
+		}
+	}
+	if delay > 0 {
+		return delay
+	}
+
+	for i := range l.limits {
+		l.calls[i] = append(l.calls[i], now)
+	}
+	return 0
+}
+
+// prune drops the calls that fell out of the i-th window and returns the rest.
+func (l *RateLimiter) prune(i int, now time.Time) []time.Time {
+	calls := l.calls[i]
+	start := now.Add(-l.limits[i].Window)
+	n := 0
+	for n < len(calls) && !calls[n].After(start) {
+		n++
+	}
+	if n > 0 {
+		calls = append(calls[:0], calls[n:]...)
+		l.calls[i] = calls
+	}
+	return calls
+}
@@ -0,0 +1,119 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRemaining(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(RateLimit{Window: time.Minute, Budget: 2}, RateLimit{Window: time.Hour, Budget: 3})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait returned error: %v", err)
+		}
+	}
+
+	statuses := l.Remaining()
+	if statuses[0].Remaining != 0 || statuses[1].Remaining != 1 {
+		t.Errorf("Remaining = %+v, want 0 and 1 calls left", statuses)
+	}
+	if want := now.Add(time.Minute); !statuses[0].Reset.Equal(want) {
+		t.Errorf("Reset = %v, want %v", statuses[0].Reset, want)
+	}
+
+	now = now.Add(time.Minute)
+	statuses = l.Remaining()
+	if statuses[0].Remaining != 2 || statuses[1].Remaining != 1 {
+		t.Errorf("Remaining = %+v, want 2 and 1 calls left", statuses)
+	}
+}
+
+func TestRateLimiterWaitBlocks(t *testing.T) {
+	l := NewRateLimiter(RateLimit{Window: 50 * time.Millisecond, Budget: 1})
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait returned error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Second call went through after %v, want at least 50ms", elapsed)
+	}
+}
+
+func TestRateLimiterDeadline(t *testing.T) {
+	l := NewRateLimiter(RateLimit{Window: time.Hour, Budget: 1})
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Wait took %v, expected it to fail fast", elapsed)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	l := NewRateLimiter(RateLimit{Window: time.Hour, Budget: 10})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Wait(context.Background()); err != nil {
+				t.Errorf("Wait returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if remaining := l.Remaining()[0].Remaining; remaining != 0 {
+		t.Errorf("Remaining = %d, want 0", remaining)
+	}
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	setup()
+	defer teardown()
+	client.RateLimiter = NewRateLimiter(RateLimit{Window: time.Hour, Budget: 1})
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.DomainsGetListContext(ctx); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call to reach the API, got %d", calls)
+	}
+}
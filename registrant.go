@@ -34,9 +34,9 @@ type Registrant struct {
 	AuxBillingPhone, AuxBillingEmailAddress, AuxBillingOrganizationName string
 }
 
-// newRegistrant return a new registrant where all the required fields are the same.
-// Feel free to change them as needed
-func newRegistrant(
+// NewRegistrant returns a new registrant where the registrant, tech, admin and
+// billing contacts are all the same. Feel free to change them as needed.
+func NewRegistrant(
 	firstName, lastName,
 	addr1, addr2,
 	city, state, postalCode, country,
@@ -117,3 +117,17 @@ func (reg *Registrant) addValues(u url.Values) error {
 
 	return nil
 }
+
+// contacts returns the contacts to send with a call: the last non-nil one of
+// those given for the call, or the default registrant of the client.
+func (client *Client) contacts(perCall ...*Registrant) (*Registrant, error) {
+	for i := len(perCall) - 1; i >= 0; i-- {
+		if perCall[i] != nil {
+			return perCall[i], nil
+		}
+	}
+	if client.Registrant == nil {
+		return nil, errors.New("no contacts given and Registrant information on client is empty")
+	}
+	return client.Registrant, nil
+}
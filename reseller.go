@@ -0,0 +1,35 @@
+package namecheap
+
+import "context"
+
+// userNameKey is the context key of the user name set by OnBehalfOf.
+type userNameKey struct{}
+
+// OnBehalfOf returns a context that makes the calls it is passed to act on
+// behalf of the account userName instead of the client's UserName, as
+// resellers do for the accounts of their customers. The API user must be
+// allowed to act for that account.
+func OnBehalfOf(ctx context.Context, userName string) context.Context {
+	return context.WithValue(ctx, userNameKey{}, userName)
+}
+
+// userName returns the account the calls made with ctx act on behalf of.
+func (client *Client) userName(ctx context.Context) string {
+	if userName, ok := ctx.Value(userNameKey{}).(string); ok && userName != "" {
+		return userName
+	}
+	return client.UserName
+}
+
+// ForUser returns a copy of the client that acts on behalf of the account
+// userName. The copy shares the HTTP client, rate limiter, cache,
+// credentials and other collaborators of the client, so it is cheap to
+// make for every call. Its calls are attributed to userName in logs, spans
+// and audit records; setting its Audit field sends them to a sink of their
+// own.
+func (client *Client) ForUser(userName string) *Client {
+	c := *client
+	c.UserName = userName
+	c.Interceptors = append([]Interceptor(nil), client.Interceptors...)
+	return &c
+}
@@ -0,0 +1,98 @@
+package namecheap
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOnBehalfOf(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var userNames []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		userNames = append(userNames, r.FormValue("UserName"))
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	observer := &testObserver{}
+	client.Observer = observer
+	var journal bytes.Buffer
+	client.Audit = NewJournal(&journal)
+
+	ctx := context.Background()
+	if _, err := client.DomainsGetListContext(OnBehalfOf(ctx, "customer1")); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if _, err := client.DomainRenewContext(OnBehalfOf(ctx, "customer2"), "example.com", 1); err != nil {
+		t.Fatalf("DomainRenew returned error: %v", err)
+	}
+	if _, err := client.DomainsGetListContext(ctx); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+
+	want := []string{"customer1", "customer2", "anUser"}
+	if strings.Join(userNames, " ") != strings.Join(want, " ") {
+		t.Errorf("UserNames sent = %v, want %v", userNames, want)
+	}
+	for i, info := range observer.calls {
+		if info.UserName != want[i] {
+			t.Errorf("Call %d observed for %q, want %q", i, info.UserName, want[i])
+		}
+	}
+	rec, err := VerifyJournal(&journal)
+	if err != nil {
+		t.Fatalf("VerifyJournal returned error: %v", err)
+	}
+	if rec.Seq != 1 || rec.ApiUser != "anApiUser" || rec.UserName != "customer2" {
+		t.Errorf("Audit record %+v, want one renewal by anApiUser for customer2", rec)
+	}
+}
+
+func TestForUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var userNames []string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		userNames = append(userNames, r.FormValue("UserName"))
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response"></ApiResponse>`)
+	})
+
+	intercepted := 0
+	client.Interceptors = []Interceptor{
+		func(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error) {
+			intercepted++
+			return next(ctx, inv)
+		},
+	}
+
+	customer := client.ForUser("customer1")
+	customer.Interceptors = append(customer.Interceptors, func(ctx context.Context, inv *Invocation, next Invoker) (*ApiResponse, error) {
+		return next(ctx, inv)
+	})
+
+	if _, err := customer.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if _, err := client.DomainsGetList(); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+	if _, err := customer.DomainsGetListContext(OnBehalfOf(context.Background(), "customer2")); err != nil {
+		t.Fatalf("DomainsGetList returned error: %v", err)
+	}
+
+	if got, want := strings.Join(userNames, " "), "customer1 anUser customer2"; got != want {
+		t.Errorf("UserNames sent = %v, want %v", got, want)
+	}
+	if intercepted != 3 {
+		t.Errorf("Interceptor of the client ran %d times, want 3", intercepted)
+	}
+	if client.UserName != "anUser" || len(client.Interceptors) != 1 {
+		t.Errorf("ForUser modified the client: %+v", client)
+	}
+}
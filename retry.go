@@ -0,0 +1,150 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy describes how a Client retries calls that failed with a
+// transport error or a 5xx response.
+//
+// Read-only commands and writes that are safe to repeat (such as
+// domains.dns.setHosts) are retried on any such failure. Billable commands
+// (domains.create, domains.renew, ssl.create, whoisguard.renew) are only
+// retried when the connection could not be established at all, since any
+// later failure may have left the charge in place. Failures that happen
+// before the request is sent, such as those of the Credentials of the
+// client, are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// BaseDelay is the backoff before the first retry. It doubles with
+	// every further attempt, and the actual delay is randomized between
+	// zero and that value.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the backoff between two attempts.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is a reasonable policy for interactive use.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// shouldRetry reports whether a command that failed with the given status or
+// error may be sent again.
+func (policy *RetryPolicy) shouldRetry(kind CommandKind, status int, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		if kind == BillableCommand {
+			return isDialError(err)
+		}
+		return isTransportError(err)
+	}
+	return status >= http.StatusInternalServerError && kind != BillableCommand
+}
+
+// backoff returns the randomized delay to wait after the given attempt.
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.BaseDelay
+	for i := 1; i < attempt && (policy.MaxDelay <= 0 || delay < policy.MaxDelay); i++ {
+		delay *= 2
+	}
+	if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// isTransportError reports whether err is a failure of the HTTP exchange,
+// as opposed to one that happened before anything was sent, such as a
+// failure of the Credentials of the client or an invalid BaseURL.
+func isTransportError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && urlErr.Op != "parse"
+}
+
+// isDialError reports whether err happened while connecting, that is before
+// any part of the request could have reached the API.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// sendRequestWithRetry sends request, retrying it as allowed by the client's
+// RetryPolicy. Every attempt goes through the client's CircuitBreaker and is
+// paced by its RateLimiter. The caller must close the body of the response.
+func (client *Client) sendRequestWithRetry(ctx context.Context, request *ApiRequest) (*http.Response, error) {
+	policy, breaker := client.RetryPolicy, client.CircuitBreaker
+	for attempt := 1; ; attempt++ {
+		var ticket circuitTicket
+		if breaker != nil {
+			var err error
+			if ticket, err = breaker.allow(); err != nil {
+				return nil, err
+			}
+		}
+		if client.RateLimiter != nil {
+			start := time.Now()
+			err := client.RateLimiter.Wait(ctx)
+			if client.Observer != nil {
+				client.Observer.ObserveRateLimitWait(request.command, time.Since(start))
+			}
+			if err != nil {
+				if breaker != nil {
+					breaker.record(ticket, 0, err)
+				}
+				return nil, err
+			}
+		}
+
+		resp, err := client.sendRequest(ctx, request)
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		if breaker != nil {
+			breaker.record(ticket, status, err)
+		}
+		if policy == nil || attempt >= policy.MaxAttempts || !policy.shouldRetry(request.commandKind(), status, err) {
+			return resp, err
+		}
+		if resp != nil {
+			discard(resp)
+		}
+		if client.Observer != nil {
+			client.Observer.ObserveRetry(request.command)
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// discard drains and closes the body of a response that will not be used,
+// so that its connection can be reused.
+func discard(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
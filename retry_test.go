@@ -0,0 +1,183 @@
+package namecheap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = &RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    2 * time.Millisecond,
+}
+
+func TestRetryReadCommand(t *testing.T) {
+	setup()
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy
+
+	attempts := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><ApiResponse Status="OK" xmlns="http://api.namecheap.com/xml.response">
+  <CommandResponse Type="namecheap.domains.dns.getHosts">
+    <DomainDNSGetHostsResult Domain="domain.com" IsUsingOurDNS="true" />
+  </CommandResponse>
+</ApiResponse>`)
+	})
+
+	result, err := client.DomainsDNSGetHosts("domain", "com")
+	if err != nil {
+		t.Fatalf("DomainsDNSGetHosts returned error: %v", err)
+	}
+	if result.Domain != "domain.com" {
+		t.Errorf("DomainsDNSGetHosts returned domain %q, want %q", result.Domain, "domain.com")
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	setup()
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy
+
+	attempts := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	if _, err := client.DomainDNSSetHosts("domain", "com", nil); err == nil {
+		t.Error("Expected error after exhausting retries")
+	}
+	if attempts != testRetryPolicy.MaxAttempts {
+		t.Errorf("Expected %d attempts, got %d", testRetryPolicy.MaxAttempts, attempts)
+	}
+}
+
+func TestRetrySkipsBillableCommands(t *testing.T) {
+	setup()
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy
+
+	attempts := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	if _, err := client.DomainRenew("domain.com", 1); err == nil {
+		t.Error("Expected error for non-200 response")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt for a billable command, got %d", attempts)
+	}
+}
+
+func TestRetryBillableDialError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	c := NewClient("anApiUser", "anToken", "anUser")
+	c.BaseURL = "http://" + addr + "/"
+	c.RetryPolicy = testRetryPolicy
+
+	dials := 0
+	c.HttpClient = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dials++
+			return (&net.Dialer{}).DialContext(ctx, network, address)
+		},
+	}}
+
+	if _, err := c.WhoisguardRenew(1, 1); !isDialError(err) {
+		t.Errorf("Expected a dial error, got %v", err)
+	}
+	if dials != testRetryPolicy.MaxAttempts {
+		t.Errorf("Expected %d dial attempts, got %d", testRetryPolicy.MaxAttempts, dials)
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := client.DomainsGetListContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled during backoff, got %v", err)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt := 1; attempt < 10; attempt++ {
+		delay := policy.backoff(attempt)
+		if delay < 0 || delay > policy.MaxDelay {
+			t.Errorf("backoff(%d) = %v, want between 0 and %v", attempt, delay, policy.MaxDelay)
+		}
+	}
+}
+
+// countingCredentials is a CredentialsProvider counting how many times it
+// was asked for a key, which it fails to give if err is set.
+type countingCredentials struct {
+	calls int
+	err   error
+}
+
+func (c *countingCredentials) ApiKey(ctx context.Context) (string, error) {
+	c.calls++
+	return "anToken", c.err
+}
+
+func TestRetrySkipsLocalErrors(t *testing.T) {
+	setup()
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy
+
+	attempts := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	})
+
+	creds := &countingCredentials{err: errors.New("vault is sealed")}
+	client.Credentials = creds
+	if _, err := client.DomainsGetList(); err == nil {
+		t.Error("Expected error from the credentials provider")
+	}
+	if creds.calls != 1 || attempts != 0 {
+		t.Errorf("Expected a single attempt that sent nothing, got %d calls of the provider and %d requests", creds.calls, attempts)
+	}
+
+	creds = &countingCredentials{}
+	client.Credentials = creds
+	client.BaseURL = "http://[::1"
+	if _, err := client.DomainsGetList(); err == nil {
+		t.Error("Expected error for an invalid BaseURL")
+	}
+	if creds.calls != 1 {
+		t.Errorf("Expected a single attempt with an invalid BaseURL, got %d", creds.calls)
+	}
+}
@@ -1,6 +1,7 @@
 package namecheap
 
 import (
+	"context"
 	"net/url"
 	"strconv"
 )
@@ -69,46 +70,78 @@ type SslDns struct {
 	Target      string `xml:"Target,omitempty"`
 }
 
+// The CommandResponse elements the results of the commands are decoded from.
+type (
+	sslGetListResponse struct {
+		SslCertificates []SslGetListResult `xml:"SSLListResult>SSL"`
+	}
+	sslCreateResponse struct {
+		SslCreate *SslCreateResult `xml:"SSLCreateResult"`
+	}
+	sslActivateResponse struct {
+		SslActivate *SslActivateResult `xml:"SSLActivateResult"`
+	}
+)
+
 // SslGetList gets a list of SSL certificates for a particular user
 func (client *Client) SslGetList() ([]SslGetListResult, error) {
+	return client.SslGetListContext(context.Background())
+}
+
+// SslGetListContext is like SslGetList but takes a context.
+func (client *Client) SslGetListContext(ctx context.Context) ([]SslGetListResult, error) {
+	var result sslGetListResponse
 	requestInfo := &ApiRequest{
 		command: sslGetList,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.SslCertificates, nil
+	return result.SslCertificates, nil
 }
 
 // SslCreate creates a new SSL certificate by purchasing it using the account funds
 func (client *Client) SslCreate(productType string, years int) (*SslCreateResult, error) {
+	return client.SslCreateContext(context.Background(), productType, years)
+}
+
+// SslCreateContext is like SslCreate but takes a context.
+func (client *Client) SslCreateContext(ctx context.Context, productType string, years int) (*SslCreateResult, error) {
+	var result sslCreateResponse
 	requestInfo := &ApiRequest{
 		command: sslCreate,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 	requestInfo.params.Set("Type", productType)
 	requestInfo.params.Set("Years", strconv.Itoa(years))
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.SslCreate, nil
+	return result.SslCreate, nil
 }
 
 // SslActivate activates a purchased and non-activated SSL certificate
 func (client *Client) SslActivate(params SslActivateParams) (*SslActivateResult, error) {
+	return client.SslActivateContext(context.Background(), params)
+}
+
+// SslActivateContext is like SslActivate but takes a context.
+func (client *Client) SslActivateContext(ctx context.Context, params SslActivateParams) (*SslActivateResult, error) {
+	var result sslActivateResponse
 	requestInfo := &ApiRequest{
 		command: sslActivate,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 	requestInfo.params.Set("CertificateID", strconv.Itoa(params.CertificateId))
 	requestInfo.params.Set("CSR", params.Csr)
@@ -127,10 +160,9 @@ func (client *Client) SslActivate(params SslActivateParams) (*SslActivateResult,
 		requestInfo.params.Set("ApproverEmail", params.ApproverEmail)
 	}
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.SslActivate, nil
+	return result.SslActivate, nil
 }
@@ -1,11 +1,13 @@
 package namecheap
 
 import (
+	"context"
 	"net/url"
 )
 
 const (
-	usersGetPricing = "namecheap.users.getPricing"
+	usersGetPricing  = "namecheap.users.getPricing"
+	usersGetBalances = "namecheap.users.getBalances"
 )
 
 type UsersGetPricingResult struct {
@@ -27,18 +29,64 @@ type UsersGetPricingResult struct {
 	} `xml:"ProductCategory"`
 }
 
+type UsersGetBalancesResult struct {
+	Currency                  string  `xml:"Currency,attr"`
+	AvailableBalance          float64 `xml:"AvailableBalance,attr"`
+	AccountBalance            float64 `xml:"AccountBalance,attr"`
+	EarnedAmount              float64 `xml:"EarnedAmount,attr"`
+	WithdrawableAmount        float64 `xml:"WithdrawableAmount,attr"`
+	FundsRequiredForAutoRenew float64 `xml:"FundsRequiredForAutoRenew,attr"`
+}
+
+// The CommandResponse elements the results of the commands are decoded from.
+type (
+	usersGetPricingResponse struct {
+		UsersGetPricing []UsersGetPricingResult `xml:"UserGetPricingResult>ProductType"`
+	}
+	usersGetBalancesResponse struct {
+		UsersGetBalances *UsersGetBalancesResult `xml:"UserGetBalancesResult"`
+	}
+)
+
 func (client *Client) UsersGetPricing(productType string) ([]UsersGetPricingResult, error) {
+	return client.UsersGetPricingContext(context.Background(), productType)
+}
+
+// UsersGetPricingContext is like UsersGetPricing but takes a context.
+func (client *Client) UsersGetPricingContext(ctx context.Context, productType string) ([]UsersGetPricingResult, error) {
+	var result usersGetPricingResponse
 	requestInfo := &ApiRequest{
 		command: usersGetPricing,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
 	requestInfo.params.Set("ProductType", productType)
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
+		return nil, err
+	}
+
+	return result.UsersGetPricing, nil
+}
+
+func (client *Client) UsersGetBalances() (*UsersGetBalancesResult, error) {
+	return client.UsersGetBalancesContext(context.Background())
+}
+
+// UsersGetBalancesContext is like UsersGetBalances but takes a context.
+func (client *Client) UsersGetBalancesContext(ctx context.Context) (*UsersGetBalancesResult, error) {
+	var result usersGetBalancesResponse
+	requestInfo := &ApiRequest{
+		command: usersGetBalances,
+		method:  "POST",
+		params:  url.Values{},
+		result:  &result,
+	}
+
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.UsersGetPricing, nil
+	return result.UsersGetBalances, nil
 }
@@ -1,6 +1,7 @@
 package namecheap
 
 import (
+	"context"
 	"errors"
 	"net/url"
 	"strconv"
@@ -39,32 +40,61 @@ type WhoisguardRenewResult struct {
 	TransactionID int     `xml:"TransactionId,attr"`
 }
 
+// The CommandResponse elements the results of the commands are decoded from.
+type (
+	whoisguardGetListResponse struct {
+		WhoisguardList []WhoisguardGetListResult `xml:"WhoisguardGetListResult>Whoisguard"`
+	}
+	whoisguardEnableResponse struct {
+		WhoisguardEnable whoisguardEnableResult `xml:"WhoisguardEnableResult"`
+	}
+	whoisguardDisableResponse struct {
+		WhoisguardDisable whoisguardDisableResult `xml:"WhoisguardDisableResult"`
+	}
+	whoisguardRenewResponse struct {
+		WhoisguardRenew *WhoisguardRenewResult `xml:"WhoisguardRenewResult"`
+	}
+)
+
 func (client *Client) WhoisguardGetList() ([]WhoisguardGetListResult, error) {
+	return client.WhoisguardGetListContext(context.Background())
+}
+
+// WhoisguardGetListContext is like WhoisguardGetList but takes a context.
+func (client *Client) WhoisguardGetListContext(ctx context.Context) ([]WhoisguardGetListResult, error) {
+	var result whoisguardGetListResponse
 	requestInfo := &ApiRequest{
 		command: whoisguardGetList,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.WhoisguardList, nil
+	return result.WhoisguardList, nil
 }
 
 func (client *Client) WhoisguardEnable(id int64, email string) error {
+	return client.WhoisguardEnableContext(context.Background(), id, email)
+}
+
+// WhoisguardEnableContext is like WhoisguardEnable but takes a context.
+func (client *Client) WhoisguardEnableContext(ctx context.Context, id int64, email string) error {
+	var result whoisguardEnableResponse
 	requestInfo := &ApiRequest{
 		command: whoisguardEnable,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
 	requestInfo.params.Set("WhoisguardID", strconv.FormatInt(id, 10))
 	requestInfo.params.Set("ForwardedToEmail", email)
-	resp, err := client.do(requestInfo)
-	if err == nil && !resp.WhoisguardEnable.IsSuccess {
+	_, err := client.do(ctx, requestInfo)
+	if err == nil && !result.WhoisguardEnable.IsSuccess {
 		err = errors.New("IsSuccess was false")
 	}
 
@@ -72,15 +102,22 @@ func (client *Client) WhoisguardEnable(id int64, email string) error {
 }
 
 func (client *Client) WhoisguardDisable(id int64) error {
+	return client.WhoisguardDisableContext(context.Background(), id)
+}
+
+// WhoisguardDisableContext is like WhoisguardDisable but takes a context.
+func (client *Client) WhoisguardDisableContext(ctx context.Context, id int64) error {
+	var result whoisguardDisableResponse
 	requestInfo := &ApiRequest{
 		command: whoisguardDisable,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
 	requestInfo.params.Set("WhoisguardID", strconv.FormatInt(id, 10))
-	resp, err := client.do(requestInfo)
-	if err == nil && !resp.WhoisguardDisable.IsSuccess {
+	_, err := client.do(ctx, requestInfo)
+	if err == nil && !result.WhoisguardDisable.IsSuccess {
 		err = errors.New("IsSuccess was false")
 	}
 
@@ -88,18 +125,24 @@ func (client *Client) WhoisguardDisable(id int64) error {
 }
 
 func (client *Client) WhoisguardRenew(id int64, years int) (*WhoisguardRenewResult, error) {
+	return client.WhoisguardRenewContext(context.Background(), id, years)
+}
+
+// WhoisguardRenewContext is like WhoisguardRenew but takes a context.
+func (client *Client) WhoisguardRenewContext(ctx context.Context, id int64, years int) (*WhoisguardRenewResult, error) {
+	var result whoisguardRenewResponse
 	requestInfo := &ApiRequest{
 		command: whoisguardRenew,
 		method:  "POST",
 		params:  url.Values{},
+		result:  &result,
 	}
 
 	requestInfo.params.Set("WhoisguardID", strconv.FormatInt(id, 10))
 	requestInfo.params.Set("Years", strconv.Itoa(years))
-	resp, err := client.do(requestInfo)
-	if err != nil {
+	if _, err := client.do(ctx, requestInfo); err != nil {
 		return nil, err
 	}
 
-	return resp.WhoisguardRenew, nil
+	return result.WhoisguardRenew, nil
 }